@@ -0,0 +1,61 @@
+package xerr
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// newErrorID returns a random identifier for a handled error
+func newErrorID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// recordError remembers the handled error and its ID on the request so the access log can include them
+func recordError(r *http.Request, err interface{}, id string) {
+	state := stateFromRequest(r)
+	if state == nil {
+		return
+	}
+
+	state.errID = id
+	state.errType = ErrUnknown
+	if e, ok := err.(error); ok {
+		state.errType = TypeOf(e)
+	}
+}
+
+// logAccess writes one structured access log line for the request
+func (eh *ErrorHandler) logAccess(r *http.Request, status int, state *requestState) {
+	if eh.config.AccessLog == nil {
+		return
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Int("status", status),
+		slog.Duration("duration", time.Since(state.start)),
+		slog.String("remote_addr", r.RemoteAddr),
+		slog.String("user_agent", r.UserAgent()),
+	}
+	if state.errID != "" {
+		attrs = append(attrs,
+			slog.String("error_id", state.errID),
+			slog.Int("error_type", int(state.errType)),
+		)
+	}
+
+	level := slog.LevelInfo
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	eh.config.AccessLog.LogAttrs(r.Context(), level, "request", attrs...)
+}
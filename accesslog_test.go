@@ -0,0 +1,89 @@
+package xerr
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accessLogLines decodes the JSON lines written to buf
+func accessLogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestMiddlewareWritesAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	eh := NewErrorHandler(&Config{AccessLog: slog.New(slog.NewJSONHandler(&buf, nil))})
+
+	h := eh.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/panic":
+			panic("boom")
+		case "/handled":
+			eh.HandleError(w, r, New("not allowed", errNotFound, nil))
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/handled", nil))
+
+	lines := accessLogLines(t, &buf)
+	require.Len(t, lines, 3)
+
+	assert.Equal(t, "INFO", lines[0]["level"])
+	assert.Equal(t, "POST", lines[0]["method"])
+	assert.Equal(t, "/orders", lines[0]["path"])
+	assert.EqualValues(t, http.StatusCreated, lines[0]["status"])
+	assert.NotContains(t, lines[0], "error_id")
+
+	assert.Equal(t, "ERROR", lines[1]["level"])
+	assert.EqualValues(t, http.StatusInternalServerError, lines[1]["status"])
+	assert.Len(t, lines[1]["error_id"], 16)
+	assert.EqualValues(t, ErrUnknown, lines[1]["error_type"])
+
+	assert.EqualValues(t, errNotFound, lines[2]["error_type"])
+	assert.NotEqual(t, lines[1]["error_id"], lines[2]["error_id"])
+}
+
+func TestErrorPageShowsErrorID(t *testing.T) {
+	var buf bytes.Buffer
+	eh := NewErrorHandler(&Config{AccessLog: slog.New(slog.NewJSONHandler(&buf, nil))})
+	h := eh.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { panic("boom") }))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	id := accessLogLines(t, &buf)[0]["error_id"].(string)
+	assert.Contains(t, w.Body.String(), id)
+}
+
+func TestAccessLogWithPublicJSON(t *testing.T) {
+	var buf bytes.Buffer
+	eh := NewErrorHandler(&Config{PublicJSON: true, AccessLog: slog.New(slog.NewJSONHandler(&buf, nil))})
+
+	h := eh.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eh.HandleError(w, r, New("not allowed", errNotFound, nil))
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	lines := accessLogLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Len(t, lines[0]["error_id"], 16)
+	assert.Equal(t, float64(errNotFound), lines[0]["error_type"])
+}
@@ -0,0 +1,62 @@
+package xerr
+
+import (
+	"net/http"
+	"sync"
+)
+
+// maxCachedResponses bounds the number of public error bodies kept in memory
+const maxCachedResponses = 1024
+
+// responseKey identifies public error responses with identical bodies
+type responseKey struct {
+	Type    ErrorType
+	Status  int
+	Message string
+}
+
+// responseCache keeps serialized public error bodies that don't carry details,
+// so identical errors on hot paths are not serialized again for every request.
+type responseCache struct {
+	mu      sync.RWMutex
+	entries map[responseKey][]byte
+}
+
+func newResponseCache() *responseCache {
+	return &responseCache{entries: make(map[responseKey][]byte)}
+}
+
+// response returns the status and JSON body for err, serializing it only once per key
+func (c *responseCache) response(err interface{}) (int, []byte) {
+	pe := NewPublicError(err)
+	if len(pe.Details) > 0 {
+		return PublicResponse(err)
+	}
+
+	key := responseKey{Type: pe.Type, Status: pe.Status, Message: pe.Message}
+	c.mu.RLock()
+	body, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok {
+		return pe.Status, body
+	}
+
+	status, body := PublicResponse(err)
+	c.mu.Lock()
+	if len(c.entries) < maxCachedResponses {
+		c.entries[key] = body
+	}
+	c.mu.Unlock()
+	return status, body
+}
+
+// writePublic writes the client facing JSON response for err
+func (eh *ErrorHandler) writePublic(w http.ResponseWriter, err interface{}) {
+	status, body := eh.responses.response(err)
+	for key, value := range rateLimitHeaders(err) {
+		w.Header().Set(key, value)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,85 @@
+package xerr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleErrorWritesPublicJSON(t *testing.T) {
+	SetStatusCode(errNotFound, http.StatusNotFound)
+	eh := NewErrorHandler(&Config{PublicJSON: true})
+
+	w := httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/old", nil), New("route removed in v2", errNotFound, nil).WithPublicMessage("Not here anymore"))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"status": 404, "type": 5000, "message": "Not here anymore"}`, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "route removed in v2")
+}
+
+func TestResponseCacheReusesIdenticalBodies(t *testing.T) {
+	SetStatusCode(errNotFound, http.StatusNotFound)
+	c := newResponseCache()
+
+	status, first := c.response(New("a", errNotFound, nil).WithPublicMessage("Gone"))
+	_, second := c.response(New("b", errNotFound, nil).WithPublicMessage("Gone"))
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, string(first), string(second))
+	assert.Same(t, &first[0], &second[0], "Identical public errors should share the cached body")
+	assert.Len(t, c.entries, 1)
+
+	_, other := c.response(New("c", errNotFound, nil).WithPublicMessage("Moved"))
+	assert.Contains(t, string(other), "Moved")
+	assert.Len(t, c.entries, 2)
+}
+
+func TestResponseCacheSkipsDetails(t *testing.T) {
+	c := newResponseCache()
+
+	_, body := c.response(New("invalid", ErrUnknown, nil).WithDetails(map[string]any{"field": "email"}))
+	assert.Contains(t, string(body), `"field":"email"`)
+	_, body = c.response(New("invalid", ErrUnknown, nil).WithDetails(map[string]any{"field": "name"}))
+	assert.Contains(t, string(body), `"field":"name"`)
+	assert.Empty(t, c.entries)
+}
+
+// BenchmarkResponseCache compares cached public bodies with serializing them every time
+func BenchmarkResponseCache(b *testing.B) {
+	err := New("boom", ErrUnknown, nil).WithPublicMessage("Something failed")
+
+	b.Run("cached", func(b *testing.B) {
+		c := newResponseCache()
+		for b.Loop() {
+			c.response(err)
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		for b.Loop() {
+			PublicResponse(err)
+		}
+	})
+}
+
+// BenchmarkHandleErrorPublicJSON compares public JSON errors with and without a reporter,
+// which requires building the ErrorData
+func BenchmarkHandleErrorPublicJSON(b *testing.B) {
+	err := New("boom", ErrUnknown, nil).WithPublicMessage("Something failed")
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	b.Run("public only", func(b *testing.B) {
+		eh := NewErrorHandler(&Config{PublicJSON: true, MaxFrames: 50})
+		for b.Loop() {
+			eh.HandleError(httptest.NewRecorder(), r, err)
+		}
+	})
+	b.Run("with reporter", func(b *testing.B) {
+		eh := NewErrorHandler(&Config{PublicJSON: true, MaxFrames: 50, Reporters: []Reporter{&projectingReporter{}}})
+		for b.Loop() {
+			eh.HandleError(httptest.NewRecorder(), r, err)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package xerr
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strings"
+)
+
+// defaultChaosMessage is the message of injected errors when Chaos.Message is empty
+const defaultChaosMessage = "chaos: synthetic failure injected by xerr"
+
+// Chaos injects synthetic failures so error pages, alerts and dashboards can be
+// verified before a real incident. It is meant for development and staging:
+// nothing is injected unless DebugMode is on and Environment isn't "production".
+type Chaos struct {
+	Routes      []string  `json:"routes"`      // Path prefixes to inject failures on (all routes when empty)
+	Probability float64   `json:"probability"` // Chance of injecting a failure on a matching request (0 to 1)
+	Panic       bool      `json:"panic"`       // Panic instead of returning a synthetic error
+	Type        ErrorType `json:"type"`        // Type of the injected error (ErrUnknown when zero)
+	Message     string    `json:"message"`     // Message of the injected error (a default one when empty)
+}
+
+// matches reports whether a failure should be injected into r
+func (c *Chaos) matches(r *http.Request) bool {
+	if c == nil || c.Probability <= 0 {
+		return false
+	}
+
+	if len(c.Routes) > 0 {
+		matched := false
+		for _, route := range c.Routes {
+			if strings.HasPrefix(r.URL.Path, route) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return rand.Float64() < c.Probability
+}
+
+// chaosError builds the synthetic error injected into r
+func (c *Chaos) chaosError(r *http.Request) *XErr {
+	msg := c.Message
+	if msg == "" {
+		msg = defaultChaosMessage
+	}
+	return New(msg, c.Type, nil).
+		WithDetails(map[string]any{"chaos": true, "path": r.URL.Path})
+}
+
+// chaosEnabled reports whether the configuration allows injecting failures
+func (eh *ErrorHandler) chaosEnabled() bool {
+	return eh.config.DebugMode && eh.config.Environment != "production"
+}
+
+// injectChaos fails the request when the chaos configuration picks it.
+// It reports whether the request was handled.
+func (eh *ErrorHandler) injectChaos(w http.ResponseWriter, r *http.Request) bool {
+	if !eh.chaosEnabled() || !eh.config.Chaos.matches(r) {
+		return false
+	}
+
+	err := eh.config.Chaos.chaosError(r)
+	if eh.config.Chaos.Panic {
+		panic(err)
+	}
+	eh.HandleError(w, r, err)
+	return true
+}
@@ -0,0 +1,85 @@
+package xerr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChaosMatches(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/orders", nil)
+
+	var none *Chaos
+	assert.False(t, none.matches(r))
+	assert.False(t, (&Chaos{}).matches(r))
+	assert.True(t, (&Chaos{Probability: 1}).matches(r))
+	assert.True(t, (&Chaos{Probability: 1, Routes: []string{"/admin", "/api/"}}).matches(r))
+	assert.False(t, (&Chaos{Probability: 1, Routes: []string{"/admin"}}).matches(r))
+}
+
+func TestMiddlewareInjectsChaosErrors(t *testing.T) {
+	rr := &recordingReporter{}
+	eh := NewErrorHandler(&Config{
+		DebugMode: true,
+		Reporters: []Reporter{rr},
+		Chaos:     &Chaos{Probability: 1, Routes: []string{"/api/"}},
+	})
+
+	called := 0
+	h := eh.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called++ }))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/orders", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, 0, called, "The handler should not run when a failure is injected")
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, 1, called)
+
+	assert.NoError(t, eh.Flush(context.Background()))
+	assert.Len(t, rr.received(), 1)
+	assert.Contains(t, rr.received()[0].Error, "chaos: synthetic failure")
+}
+
+func TestMiddlewareInjectsChaosPanics(t *testing.T) {
+	eh := NewErrorHandler(&Config{DebugMode: true, Chaos: &Chaos{Probability: 1, Panic: true}})
+	h := eh.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "chaos: synthetic failure")
+}
+
+func TestMiddlewareInjectsConfiguredChaosError(t *testing.T) {
+	const errChaos ErrorType = 4200
+	SetStatusCode(errChaos, http.StatusServiceUnavailable)
+	eh := NewErrorHandler(&Config{DebugMode: true, Chaos: &Chaos{Probability: 1, Type: errChaos, Message: "payments down"}})
+	h := eh.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "payments down")
+}
+
+func TestChaosOnlyInDevelopment(t *testing.T) {
+	for name, config := range map[string]*Config{
+		"debug mode off": {Environment: "staging"},
+		"production":     {DebugMode: true, Environment: "production"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			config.Chaos = &Chaos{Probability: 1, Panic: true}
+			called := false
+			h := NewErrorHandler(config).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.True(t, called)
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+}
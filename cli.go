@@ -0,0 +1,113 @@
+package xerr
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+)
+
+// DefaultExitCode is the exit code used for errors without a registered mapping
+const DefaultExitCode = 1
+
+var (
+	exitCodesMu sync.RWMutex
+	exitCodes   = map[ErrorType]int{}
+)
+
+// osExit is replaced in tests
+var osExit = os.Exit
+
+// SetExitCode maps an ErrorType to the process exit code returned by ExitCode
+func SetExitCode(t ErrorType, code int) {
+	exitCodesMu.Lock()
+	defer exitCodesMu.Unlock()
+	exitCodes[t] = code
+}
+
+// ExitCode returns the process exit code for err.
+// It returns 0 for nil, the mapped code for an XErr in the chain, or DefaultExitCode.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	xe, ok := As(err)
+	if !ok {
+		return DefaultExitCode
+	}
+
+	exitCodesMu.RLock()
+	defer exitCodesMu.RUnlock()
+	if code, ok := exitCodes[xe.Type]; ok {
+		return code
+	}
+	return DefaultExitCode
+}
+
+// Exit prints an error report to stderr, colored on terminals, and exits with ExitCode(err).
+// It does nothing when err is nil.
+func Exit(err error) {
+	if err == nil {
+		return
+	}
+	PrintReport(os.Stderr, err)
+	osExit(ExitCode(err))
+}
+
+// ANSI color codes used by PrintReport
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorBold  = "\033[1m"
+	colorDim   = "\033[2m"
+)
+
+// PrintReport writes a human readable error report with the stack trace to w.
+// Colors are only used when w is a terminal and the NO_COLOR environment variable is not set.
+func PrintReport(w io.Writer, err error) {
+	color := useColor(w)
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + colorReset
+	}
+
+	_, _ = fmt.Fprintf(w, "%s %s\n", paint(colorBold+colorRed, "error:"), err.Error())
+
+	xe, ok := As(err)
+	if !ok {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "%s %d\n", paint(colorDim, "type:"), xe.Type)
+	for _, key := range slices.Sorted(maps.Keys(xe.Details)) {
+		_, _ = fmt.Fprintf(w, "%s %v\n", paint(colorDim, key+":"), xe.Details[key])
+	}
+
+	frames := Frames(err)
+	if len(frames) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, paint(colorBold, "stack:"))
+	for _, f := range frames {
+		_, _ = fmt.Fprintf(w, "  %s\n      %s\n", f.Function, paint(colorDim, fmt.Sprintf("%s:%d", f.File, f.Line)))
+	}
+}
+
+// useColor reports whether w is a terminal that should get ANSI colors, so redirected
+// output and CI logs stay plain
+func useColor(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,64 @@
+package xerr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const errCLIUsage ErrorType = iota + 3000
+
+func TestExitCode(t *testing.T) {
+	SetExitCode(errCLIUsage, 64)
+
+	assert.Equal(t, 0, ExitCode(nil))
+	assert.Equal(t, DefaultExitCode, ExitCode(errors.New("plain")))
+	assert.Equal(t, DefaultExitCode, ExitCode(New("unknown", ErrUnknown, nil)))
+	assert.Equal(t, 64, ExitCode(New("bad flag", errCLIUsage, nil)))
+	assert.Equal(t, 64, ExitCode(fmt.Errorf("wrapped: %w", New("bad flag", errCLIUsage, nil))))
+}
+
+func TestPrintReport(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	var buf bytes.Buffer
+	PrintReport(&buf, New("bad flag", errCLIUsage, nil).WithDetails(map[string]any{"flag": "--port"}))
+
+	out := buf.String()
+	assert.Contains(t, out, "error: bad flag")
+	assert.Contains(t, out, "type: 3000")
+	assert.Contains(t, out, "flag: --port")
+	assert.Contains(t, out, "TestPrintReport")
+	assert.NotContains(t, out, colorReset)
+}
+
+func TestExit(t *testing.T) {
+	SetExitCode(errCLIUsage, 64)
+
+	code := -1
+	osExit = func(c int) { code = c }
+	defer func() { osExit = os.Exit }()
+
+	Exit(nil)
+	assert.Equal(t, -1, code, "Exit(nil) should not exit")
+
+	Exit(New("bad flag", errCLIUsage, nil))
+	assert.Equal(t, 64, code)
+}
+
+func TestPrintReportWithoutTerminal(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	var buf bytes.Buffer
+	PrintReport(&buf, errors.New("plain"))
+	assert.Equal(t, "error: plain\n", buf.String())
+
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.False(t, useColor(f), "Redirected output must not be colored")
+}
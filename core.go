@@ -0,0 +1,102 @@
+package xerr
+
+import (
+	"time"
+
+	"github.com/iMohamedSheta/xerr/core"
+)
+
+// The error model lives in the lightweight core package so constrained builds
+// (tinygo, cold-start sensitive functions) can use it without the HTTP handler.
+// These aliases and wrappers keep it available from xerr.
+
+// XErr is a custom error with stack trace and type
+type XErr = core.XErr
+
+// ErrorType is an enum for categorizing errors
+type ErrorType = core.ErrorType
+
+// Frame represents a single stack frame
+type Frame = core.Frame
+
+const (
+	ErrUnknown     = core.ErrUnknown
+	ErrRateLimited = core.ErrRateLimited
+)
+
+// DefaultMaxChainDepth is the default limit of errors visited when walking a chain
+const DefaultMaxChainDepth = core.DefaultMaxChainDepth
+
+// New creates a new XErr with stack trace
+func New(msg string, t ErrorType, err error) *XErr {
+	return core.NewSkip(1, msg, t, err)
+}
+
+// RateLimited creates an ErrRateLimited error, see core.RateLimited.
+// Error responses for it carry the RateLimit-* and Retry-After headers.
+func RateLimited(limit, remaining int, resetAt time.Time) *XErr {
+	return core.RateLimitedSkip(1, limit, remaining, resetAt)
+}
+
+// Frames returns the stack carried by an error chain, see core.Frames
+func Frames(err error) []Frame {
+	return core.Frames(err)
+}
+
+// Schema describes the details an XErr of a given type must carry
+type Schema = core.Schema
+
+// RegisterSchema sets the details schema of an ErrorType
+func RegisterSchema(t ErrorType, s Schema) {
+	core.RegisterSchema(t, s)
+}
+
+// UnregisterSchema removes the details schema of an ErrorType
+func UnregisterSchema(t ErrorType) {
+	core.UnregisterSchema(t)
+}
+
+// SetSchemaValidation enables details validation, see core.SetSchemaValidation
+func SetSchemaValidation(fn func(e *XErr, err error)) {
+	core.SetSchemaValidation(fn)
+}
+
+// ValidateDetails checks details against the schema registered for t
+func ValidateDetails(t ErrorType, details map[string]any) error {
+	return core.ValidateDetails(t, details)
+}
+
+// As returns the first XErr in err's chain, stopping on cyclic or too deep chains
+func As(err error) (*XErr, bool) {
+	return core.As(err)
+}
+
+// Is reports whether an XErr of one of the given types is in err's chain, see core.Is
+func Is(err error, types ...ErrorType) bool {
+	return core.Is(err, types...)
+}
+
+// TypeOf returns the type of the first XErr in err's chain, or ErrUnknown
+func TypeOf(err error) ErrorType {
+	return core.TypeOf(err)
+}
+
+// Chain returns err and every error it wraps, see core.WalkChain
+func Chain(err error) ([]error, bool) {
+	return core.Chain(err)
+}
+
+// WalkChain calls fn for err and every error it wraps, see core.WalkChain
+func WalkChain(err error, fn func(err error, depth int) bool) bool {
+	return core.WalkChain(err, fn)
+}
+
+// SetMaxChainDepth sets how many errors the chain walking helpers visit at most
+func SetMaxChainDepth(depth int) {
+	core.SetMaxChainDepth(depth)
+}
+
+// MaxChainDepth returns the current chain depth limit
+func MaxChainDepth() int {
+	return core.MaxChainDepth()
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"reflect"
+	"slices"
+	"sync/atomic"
+)
+
+// DefaultMaxChainDepth is the default limit of errors visited when walking a chain
+const DefaultMaxChainDepth = 100
+
+var maxChainDepth atomic.Int64
+
+func init() {
+	maxChainDepth.Store(DefaultMaxChainDepth)
+}
+
+// SetMaxChainDepth sets how many errors the chain walking helpers visit at most.
+// Values below 1 restore DefaultMaxChainDepth.
+func SetMaxChainDepth(depth int) {
+	if depth < 1 {
+		depth = DefaultMaxChainDepth
+	}
+	maxChainDepth.Store(int64(depth))
+}
+
+// MaxChainDepth returns the current chain depth limit
+func MaxChainDepth() int {
+	return int(maxChainDepth.Load())
+}
+
+// WalkChain calls fn for err and every error it wraps, depth first, with the
+// nesting depth of each error. Both Unwrap() error and Unwrap() []error are followed.
+// Walking stops when fn returns false. Errors already visited are skipped so cyclic
+// chains terminate, and at most MaxChainDepth errors are visited. Only pointer errors
+// are tracked, as a cycle needs one and a value error may hold an unhashable field.
+// It reports whether the walk was cut short by the limit or a cycle.
+func WalkChain(err error, fn func(err error, depth int) bool) (truncated bool) {
+	type node struct {
+		err   error
+		depth int
+	}
+
+	limit := MaxChainDepth()
+	seen := make(map[error]struct{})
+	stack := []node{{err, 0}}
+	visited := 0
+
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n.err == nil {
+			continue
+		}
+
+		if reflect.TypeOf(n.err).Kind() == reflect.Pointer {
+			if _, ok := seen[n.err]; ok {
+				truncated = true
+				continue
+			}
+			seen[n.err] = struct{}{}
+		}
+
+		if visited >= limit {
+			return true
+		}
+		visited++
+
+		if !fn(n.err, n.depth) {
+			return truncated
+		}
+
+		switch u := n.err.(type) {
+		case interface{ Unwrap() error }:
+			stack = append(stack, node{u.Unwrap(), n.depth + 1})
+		case interface{ Unwrap() []error }:
+			children := u.Unwrap()
+			for _, child := range slices.Backward(children) {
+				stack = append(stack, node{child, n.depth + 1})
+			}
+		}
+	}
+	return truncated
+}
+
+// Chain returns err and every error it wraps, depth first, see WalkChain
+func Chain(err error) (chain []error, truncated bool) {
+	truncated = WalkChain(err, func(e error, _ int) bool {
+		chain = append(chain, e)
+		return true
+	})
+	return chain, truncated
+}
+
+// Is reports whether an XErr of one of the given types is in err's chain.
+// If no types are provided, it reports whether the chain contains any XErr.
+func Is(err error, types ...ErrorType) bool {
+	found := false
+	WalkChain(err, func(e error, _ int) bool {
+		if xe, ok := e.(*XErr); ok && xe.IsType(types...) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// As returns the first XErr in err's chain.
+// Unlike errors.As it stops on cyclic or too deep chains.
+func As(err error) (*XErr, bool) {
+	var found *XErr
+	WalkChain(err, func(e error, _ int) bool {
+		found, _ = e.(*XErr)
+		return found == nil
+	})
+	return found, found != nil
+}
+
+// TypeOf returns the type of the first XErr in err's chain, or ErrUnknown
+func TypeOf(err error) ErrorType {
+	if xe, ok := As(err); ok {
+		return xe.Type
+	}
+	return ErrUnknown
+}
@@ -0,0 +1,124 @@
+package core_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iMohamedSheta/xerr/core"
+)
+
+const errNotFound core.ErrorType = 404
+
+// cyclic returns two XErrs wrapping each other
+func cyclic() *core.XErr {
+	a := core.New("a", core.ErrUnknown, nil)
+	b := core.New("b", errNotFound, a)
+	a.Err = b
+	return a
+}
+
+// TestChainDetectsCycles ensures walking a cyclic chain terminates
+func TestChainDetectsCycles(t *testing.T) {
+	chain, truncated := core.Chain(cyclic())
+
+	assert.True(t, truncated)
+	assert.Len(t, chain, 2)
+}
+
+// TestErrorTruncatesCyclicChain ensures Error() terminates on a cyclic chain
+func TestErrorTruncatesCyclicChain(t *testing.T) {
+	assert.Equal(t, "a - b - ... (chain truncated)", cyclic().Error())
+}
+
+// TestChainDepthLimit ensures at most MaxChainDepth errors are visited
+func TestChainDepthLimit(t *testing.T) {
+	core.SetMaxChainDepth(3)
+	defer core.SetMaxChainDepth(0)
+
+	var err error = errors.New("root")
+	for i := range 10 {
+		err = core.New(fmt.Sprintf("level %d", i), core.ErrUnknown, err)
+	}
+
+	chain, truncated := core.Chain(err)
+	assert.True(t, truncated)
+	assert.Len(t, chain, 3)
+	assert.Equal(t, "level 9 - level 8 - level 7 - ... (chain truncated)", err.Error())
+}
+
+// TestSetMaxChainDepthRestoresDefault ensures non-positive depths restore the default
+func TestSetMaxChainDepthRestoresDefault(t *testing.T) {
+	core.SetMaxChainDepth(5)
+	core.SetMaxChainDepth(0)
+
+	assert.Equal(t, core.DefaultMaxChainDepth, core.MaxChainDepth())
+}
+
+// TestChainFollowsJoinedErrors ensures Unwrap() []error branches are walked with their depth
+func TestChainFollowsJoinedErrors(t *testing.T) {
+	first := errors.New("first")
+	second := core.New("second", errNotFound, nil)
+	joined := errors.Join(first, second)
+
+	var depths []int
+	truncated := core.WalkChain(joined, func(_ error, depth int) bool {
+		depths = append(depths, depth)
+		return true
+	})
+
+	assert.False(t, truncated)
+	assert.Equal(t, []int{0, 1, 1}, depths)
+	assert.True(t, core.Is(joined, errNotFound))
+}
+
+// TestChainHelpersOnCycle ensures Is, As and TypeOf terminate on a cyclic chain
+func TestChainHelpersOnCycle(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", cyclic())
+
+	xe, ok := core.As(err)
+	assert.True(t, ok)
+	assert.Equal(t, "a", xe.Message)
+	assert.Equal(t, core.ErrUnknown, core.TypeOf(err))
+	assert.True(t, core.Is(err, errNotFound))
+	assert.False(t, core.Is(err, core.ErrRateLimited))
+}
+
+// TestChainHelpersWithoutXErr ensures the helpers handle chains without an XErr
+func TestChainHelpersWithoutXErr(t *testing.T) {
+	err := errors.New("plain")
+
+	_, ok := core.As(err)
+	assert.False(t, ok)
+	assert.False(t, core.Is(err))
+	assert.Equal(t, core.ErrUnknown, core.TypeOf(err))
+}
+
+// multiError is a non comparable error value
+type multiError struct {
+	msgs []string
+}
+
+func (m multiError) Error() string { return fmt.Sprint(m.msgs) }
+
+// valueWrapper is a comparable error value that can hold a non comparable one
+type valueWrapper struct {
+	err error
+}
+
+func (w valueWrapper) Error() string { return "wrapped: " + w.err.Error() }
+func (w valueWrapper) Unwrap() error { return w.err }
+
+// TestChainHelpersOnUnhashableErrors ensures value errors holding unhashable fields don't panic
+func TestChainHelpersOnUnhashableErrors(t *testing.T) {
+	err := valueWrapper{err: multiError{msgs: []string{"a"}}}
+
+	assert.NotPanics(t, func() {
+		assert.Equal(t, core.ErrUnknown, core.TypeOf(err))
+	})
+	chain, truncated := core.Chain(valueWrapper{err: core.New("inner", errNotFound, err)})
+	assert.False(t, truncated)
+	assert.Len(t, chain, 4)
+}
@@ -0,0 +1,5 @@
+// Package core contains the xerr error model: XErr, ErrorType, stack frames
+// and JSON encoding. It doesn't depend on net/http or html/template, so it can
+// be imported on its own by constrained builds (tinygo, serverless functions).
+// The github.com/iMohamedSheta/xerr package re-exports everything in it.
+package core
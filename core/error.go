@@ -0,0 +1,112 @@
+package core
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// ErrorType is an enum for categorizing errors
+type ErrorType int
+
+// Built-in error types. Types defined by xerr other than ErrUnknown are negative,
+// so they never collide with application types, which should be zero or positive.
+const (
+	ErrUnknown     ErrorType = 0
+	ErrRateLimited ErrorType = -1
+)
+
+// XErr is a custom error with stack trace and type
+type XErr struct {
+	Type          ErrorType
+	Message       string
+	PublicMessage string
+	Err           error
+	stack         []uintptr
+	Details       map[string]any
+	Critical      bool // Critical errors are delivered synchronously to the critical reporter
+	detailsErr    error
+	checked       bool // Whether the details were validated, see CheckDetails
+}
+
+// Error creates a new XErr with stack trace
+func New(msg string, t ErrorType, err error) *XErr {
+	return newXErr(3, msg, t, err)
+}
+
+// NewSkip is New for helpers that wrap it: the stack trace starts skip frames
+// above the caller of NewSkip, so NewSkip(0, ...) behaves like New
+func NewSkip(skip int, msg string, t ErrorType, err error) *XErr {
+	return newXErr(3+skip, msg, t, err)
+}
+
+// newXErr creates a new XErr with the stack trace starting skip frames up
+func newXErr(skip int, msg string, t ErrorType, err error) *XErr {
+	stack := make([]uintptr, 32)
+	n := runtime.Callers(skip, stack[:])
+	return &XErr{
+		Type:    t,
+		Message: msg,
+		Err:     err,
+		stack:   stack[:n],
+	}
+}
+
+// Adds public message to the error
+func (e *XErr) WithPublicMessage(msg string) *XErr {
+	e.PublicMessage = msg
+	return e
+}
+
+// Adds details to the error.
+// They are validated against the type's schema when SetSchemaValidation is enabled.
+func (e *XErr) WithDetails(details map[string]any) *XErr {
+	e.Details = details
+	e.validateDetails()
+	return e
+}
+
+// Marks the error as critical
+func (e *XErr) WithCritical() *XErr {
+	e.Critical = true
+	return e
+}
+
+// Error joins the messages of the chain of wrapped XErrs with the cause message.
+// Cyclic or too deep chains are cut after MaxChainDepth errors.
+func (e *XErr) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Message)
+
+	seen := map[*XErr]struct{}{e: {}}
+	for cause := e.Err; cause != nil; {
+		xe, ok := cause.(*XErr)
+		if !ok {
+			fmt.Fprintf(&b, " - %v", cause)
+			break
+		}
+		if _, cyclic := seen[xe]; cyclic || len(seen) >= MaxChainDepth() {
+			b.WriteString(" - ... (chain truncated)")
+			break
+		}
+		seen[xe] = struct{}{}
+		b.WriteString(" - " + xe.Message)
+		cause = xe.Err
+	}
+	return b.String()
+}
+
+func (e *XErr) Unwrap() error {
+	return e.Err
+}
+
+// StackTrace builds structured frames (like your ErrorHandler does)
+func (e *XErr) StackTrace(showSource bool) []Frame {
+	frames := framesFromPCs(e.stack)
+	if showSource {
+		for i := range frames {
+			frames[i].Snippet = CodeSnippet(frames[i].File, frames[i].Line)
+		}
+	}
+	return frames
+}
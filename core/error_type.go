@@ -1,4 +1,4 @@
-package xerr
+package core
 
 import (
 	"slices"
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// Frame represents a single stack frame
+type Frame struct {
+	Function string // Fully qualified function name
+	File     string
+	Line     int
+	Package  string  `json:",omitempty"` // Import path of the function's package
+	Name     string  `json:",omitempty"` // Function name within the package, e.g. "(*T).Method"
+	Module   string  `json:",omitempty"` // Path of the module providing the package, empty for the standard library
+	App      bool    `json:",omitempty"` // Whether the frame belongs to the application, see NewFrame
+	PC       uintptr `json:",omitempty"` // Program counter, meaningful only within the running binary
+	Snippet  string
+	// SHA-256 of Snippet (hex), set by xerr when the frame is part of a report
+	SnippetHash string `json:",omitempty"`
+}
+
+// NewFrame describes a resolved runtime frame, without a snippet.
+// A frame belongs to the application when its package is in the main module.
+// Binaries without module information fall back to the file path: anything outside
+// the module cache, vendor directories, net/http and the runtime is the application.
+func NewFrame(fr runtime.Frame) Frame {
+	pkg, name := splitFunction(fr.Function)
+	module := moduleOf(pkg)
+	return Frame{
+		Function: fr.Function,
+		File:     fr.File,
+		Line:     fr.Line,
+		Package:  pkg,
+		Name:     name,
+		Module:   module,
+		App:      isApp(fr.File, module),
+		PC:       fr.PC,
+	}
+}
+
+// isApp reports whether a frame of file, provided by module, belongs to the application
+func isApp(file, module string) bool {
+	if main := buildModules().main; main != "" {
+		return module == main && !strings.Contains(file, "/vendor/")
+	}
+	return !strings.Contains(file, "/iMohamedSheta/xerr/") &&
+		!strings.Contains(file, "/pkg/mod/") &&
+		!strings.Contains(file, "/vendor/") &&
+		!strings.Contains(file, "net/http") &&
+		!strings.Contains(file, "runtime/")
+}
+
+// framesFromPCs resolves program counters captured by runtime.Callers
+func framesFromPCs(pcs []uintptr) []Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+	iter := runtime.CallersFrames(pcs)
+	var result []Frame
+	for {
+		fr, more := iter.Next()
+		if fr.File != "" {
+			result = append(result, NewFrame(fr))
+		}
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+// Frames returns the stack carried by err's chain, without snippets.
+// The innermost error carrying a stack wins, as it is the closest to the origin:
+// an XErr, an error with a pkg/errors style StackTrace() method, or one with a
+// go-errors style Callers() []uintptr method.
+// It returns nil when no error in the chain carries a stack.
+func Frames(err error) []Frame {
+	var pcs []uintptr
+	WalkChain(err, func(e error, _ int) bool {
+		if stack := callers(e); len(stack) > 0 {
+			pcs = stack
+		}
+		return true
+	})
+	return framesFromPCs(pcs)
+}
+
+// callers returns the program counters of the stack carried by err itself, if any
+func callers(err error) []uintptr {
+	switch e := err.(type) {
+	case *XErr:
+		return e.stack
+	case interface{ Callers() []uintptr }:
+		return e.Callers()
+	}
+	return stackTracePCs(err)
+}
+
+// stackTracePCs calls a pkg/errors style StackTrace() method, whose result is a
+// slice of uintptr based frames. Reflection avoids depending on pkg/errors.
+func stackTracePCs(err error) []uintptr {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil
+	}
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 {
+		return nil
+	}
+	if out := t.Out(0); out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	trace := m.Call(nil)[0]
+	pcs := make([]uintptr, trace.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(trace.Index(i).Uint())
+	}
+	return pcs
+}
+
+// splitFunction splits a fully qualified function name into its import path
+// and the function name within the package, e.g. "(*T).Method" or "main.func1"
+func splitFunction(function string) (pkg, name string) {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return function, ""
+	}
+	return function[:slash+1+dot], function[slash+2+dot:]
+}
+
+// modules lists the module paths of the running binary
+type modules struct {
+	main  string
+	paths []string
+}
+
+var buildModules = sync.OnceValue(func() modules {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return modules{}
+	}
+	m := modules{main: info.Main.Path}
+	if m.main != "" {
+		m.paths = append(m.paths, m.main)
+	}
+	for _, dep := range info.Deps {
+		m.paths = append(m.paths, dep.Path)
+	}
+	return m
+})
+
+// moduleOf returns the path of the module providing pkg, or "" for the
+// standard library and binaries built without module information
+func moduleOf(pkg string) string {
+	if pkg == "main" {
+		return buildModules().main
+	}
+	module := ""
+	for _, path := range buildModules().paths {
+		if (pkg == path || strings.HasPrefix(pkg, path+"/")) && len(path) > len(module) {
+			module = path
+		}
+	}
+	return module
+}
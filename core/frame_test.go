@@ -0,0 +1,131 @@
+package core_test
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iMohamedSheta/xerr/core"
+)
+
+// pkgFrame and pkgStack mirror pkg/errors' Frame and StackTrace types
+type pkgFrame uintptr
+
+type pkgStack []pkgFrame
+
+// pkgError mimics an error created by pkg/errors
+type pkgError struct {
+	msg   string
+	stack []uintptr
+}
+
+func newPkgError(msg string) *pkgError {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	return &pkgError{msg: msg, stack: pcs[:n]}
+}
+
+func (e *pkgError) Error() string { return e.msg }
+
+func (e *pkgError) StackTrace() pkgStack {
+	trace := make(pkgStack, len(e.stack))
+	for i, pc := range e.stack {
+		trace[i] = pkgFrame(pc)
+	}
+	return trace
+}
+
+// goError mimics an error created by go-errors
+type goError struct {
+	stack []uintptr
+}
+
+func (e *goError) Error() string { return "go-errors" }
+
+func (e *goError) Callers() []uintptr { return e.stack }
+
+// TestNewFrameFields ensures frames describe their package, function and module
+func TestNewFrameFields(t *testing.T) {
+	frame := core.Frames(core.New("boom", core.ErrUnknown, nil))[0]
+
+	assert.Equal(t, "github.com/iMohamedSheta/xerr/core_test.TestNewFrameFields", frame.Function)
+	assert.Equal(t, "github.com/iMohamedSheta/xerr/core_test", frame.Package)
+	assert.Equal(t, "TestNewFrameFields", frame.Name)
+	assert.Equal(t, "github.com/iMohamedSheta/xerr", frame.Module)
+	assert.True(t, frame.App)
+	assert.NotZero(t, frame.PC)
+}
+
+// TestNewFrameStandardLibrary ensures standard library frames have no module
+func TestNewFrameStandardLibrary(t *testing.T) {
+	frame := core.NewFrame(runtime.Frame{Function: "fmt.Sprintf"})
+
+	assert.Equal(t, "fmt", frame.Package)
+	assert.Equal(t, "Sprintf", frame.Name)
+	assert.Empty(t, frame.Module)
+	assert.False(t, frame.App)
+}
+
+// TestNewFrameMethodName ensures methods and closures keep their receiver and parent
+func TestNewFrameMethodName(t *testing.T) {
+	method := core.NewFrame(runtime.Frame{Function: "github.com/a/b.(*T).Method"})
+	closure := core.NewFrame(runtime.Frame{Function: "main.main.func1"})
+
+	assert.Equal(t, "github.com/a/b", method.Package)
+	assert.Equal(t, "(*T).Method", method.Name)
+	assert.Equal(t, "main", closure.Package)
+	assert.Equal(t, "main.func1", closure.Name)
+}
+
+// TestFramesOfWrappedXErr ensures the stack of a wrapped XErr is found
+func TestFramesOfWrappedXErr(t *testing.T) {
+	err := fmt.Errorf("context: %w", core.New("boom", core.ErrUnknown, nil))
+
+	frames := core.Frames(err)
+	require.NotEmpty(t, frames)
+	assert.Equal(t, "TestFramesOfWrappedXErr", frames[0].Name)
+}
+
+// TestFramesOfPkgErrorsStack ensures pkg/errors style stacks are used, innermost first
+func TestFramesOfPkgErrorsStack(t *testing.T) {
+	err := core.New("outer", core.ErrUnknown, originError())
+
+	frames := core.Frames(err)
+	require.NotEmpty(t, frames)
+	assert.Equal(t, "originError", frames[0].Name)
+}
+
+// TestFramesWithoutStack ensures nil is returned when no error carries a stack
+func TestFramesWithoutStack(t *testing.T) {
+	assert.Nil(t, core.Frames(errors.New("plain")))
+	assert.Nil(t, core.Frames(nil))
+}
+
+func originError() error {
+	return newPkgError("origin")
+}
+
+// TestFramesOfGoErrorsStack ensures go-errors style Callers() stacks are used
+func TestFramesOfGoErrorsStack(t *testing.T) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(1, pcs)
+	err := fmt.Errorf("context: %w", &goError{stack: pcs[:n]})
+
+	frames := core.Frames(err)
+	require.NotEmpty(t, frames)
+	assert.Equal(t, "TestFramesOfGoErrorsStack", frames[0].Name)
+}
+
+// TestNewFrameMainPackage ensures package main belongs to the main module
+func TestNewFrameMainPackage(t *testing.T) {
+	frame := core.NewFrame(runtime.Frame{Function: "main.main", File: "/app/main.go"})
+	vendored := core.NewFrame(runtime.Frame{Function: "github.com/iMohamedSheta/xerr/core.New", File: "/app/vendor/github.com/iMohamedSheta/xerr/core/error.go"})
+
+	assert.Equal(t, "github.com/iMohamedSheta/xerr", frame.Module)
+	assert.True(t, frame.App)
+	assert.False(t, vendored.App)
+}
@@ -0,0 +1,12 @@
+//go:build !js
+
+package core
+
+import (
+	"os"
+)
+
+// readSource reads a source file referenced by a stack frame
+func readSource(file string) ([]byte, error) {
+	return os.ReadFile(file)
+}
@@ -0,0 +1,12 @@
+//go:build js
+
+package core
+
+import (
+	"errors"
+)
+
+// readSource always fails as source files are not available in the browser
+func readSource(string) ([]byte, error) {
+	return nil, errors.New("xerr: source files are not available on js/wasm")
+}
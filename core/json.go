@@ -0,0 +1,52 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// xerrJSON is the JSON representation of an XErr
+type xerrJSON struct {
+	Type          ErrorType      `json:"type"`
+	Message       string         `json:"message"`
+	PublicMessage string         `json:"public_message,omitempty"`
+	Cause         string         `json:"cause,omitempty"`
+	Details       map[string]any `json:"details,omitempty"`
+	Critical      bool           `json:"critical,omitempty"`
+}
+
+// MarshalJSON encodes the error type, messages, details and the cause message.
+// The stack trace is not included.
+func (e *XErr) MarshalJSON() ([]byte, error) {
+	v := xerrJSON{
+		Type:          e.Type,
+		Message:       e.Message,
+		PublicMessage: e.PublicMessage,
+		Details:       e.Details,
+		Critical:      e.Critical,
+	}
+	if e.Err != nil {
+		v.Cause = e.Err.Error()
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes an XErr encoded by MarshalJSON.
+// The cause is restored as a plain error with the original message.
+func (e *XErr) UnmarshalJSON(data []byte) error {
+	var v xerrJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	e.Type = v.Type
+	e.Message = v.Message
+	e.PublicMessage = v.PublicMessage
+	e.Details = v.Details
+	e.Critical = v.Critical
+	e.Err = nil
+	if v.Cause != "" {
+		e.Err = errors.New(v.Cause)
+	}
+	return nil
+}
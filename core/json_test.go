@@ -0,0 +1,42 @@
+package core_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iMohamedSheta/xerr/core"
+)
+
+func TestXErrMarshalJSON(t *testing.T) {
+	err := core.New("card declined", ErrPaymentFailed, errors.New("insufficient funds")).
+		WithPublicMessage("Payment failed").
+		WithDetails(map[string]any{"order_id": "A-1"})
+
+	data, jsonErr := json.Marshal(err)
+	require.NoError(t, jsonErr)
+	assert.JSONEq(t, `{
+		"type": 1000,
+		"message": "card declined",
+		"public_message": "Payment failed",
+		"cause": "insufficient funds",
+		"details": {"order_id": "A-1"}
+	}`, string(data))
+}
+
+func TestXErrUnmarshalJSONRoundTrip(t *testing.T) {
+	original := core.New("card declined", ErrPaymentFailed, errors.New("insufficient funds"))
+	data, err := json.Marshal(original)
+	require.NoError(t, err)
+
+	var decoded core.XErr
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, ErrPaymentFailed, decoded.Type)
+	assert.Equal(t, original.Error(), decoded.Error())
+	assert.True(t, decoded.IsType(ErrPaymentFailed))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"type": "x"}`), &decoded))
+}
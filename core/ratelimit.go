@@ -0,0 +1,48 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// Details keys set by RateLimited
+const (
+	DetailRateLimit     = "limit"
+	DetailRateRemaining = "remaining"
+	DetailRateResetAt   = "reset_at"
+)
+
+// RateLimited creates an ErrRateLimited error for a client that exceeded limit requests.
+// remaining is the number of requests left in the current window and resetAt is when it resets.
+func RateLimited(limit, remaining int, resetAt time.Time) *XErr {
+	return rateLimited(4, limit, remaining, resetAt)
+}
+
+// RateLimitedSkip is RateLimited for helpers that wrap it, see NewSkip
+func RateLimitedSkip(skip, limit, remaining int, resetAt time.Time) *XErr {
+	return rateLimited(4+skip, limit, remaining, resetAt)
+}
+
+// rateLimited creates an ErrRateLimited error with the stack trace starting skip frames up
+func rateLimited(skip, limit, remaining int, resetAt time.Time) *XErr {
+	e := newXErr(skip, fmt.Sprintf("rate limit of %d requests exceeded", limit), ErrRateLimited, nil)
+	e.PublicMessage = "Too many requests, please retry later"
+	e.Details = map[string]any{
+		DetailRateLimit:     limit,
+		DetailRateRemaining: remaining,
+		DetailRateResetAt:   resetAt.UTC(),
+	}
+	e.validateDetails()
+	return e
+}
+
+// RateLimit returns the rate limit values of an error created by RateLimited
+func (e *XErr) RateLimit() (limit, remaining int, resetAt time.Time, ok bool) {
+	if e == nil || e.Type != ErrRateLimited {
+		return 0, 0, time.Time{}, false
+	}
+	limit, ok1 := e.Details[DetailRateLimit].(int)
+	remaining, ok2 := e.Details[DetailRateRemaining].(int)
+	resetAt, ok3 := e.Details[DetailRateResetAt].(time.Time)
+	return limit, remaining, resetAt, ok1 && ok2 && ok3
+}
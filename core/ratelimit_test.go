@@ -0,0 +1,39 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iMohamedSheta/xerr/core"
+)
+
+// TestRateLimited ensures the rate limit values are stored and can be read back
+func TestRateLimited(t *testing.T) {
+	resetAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := core.RateLimited(100, 0, resetAt)
+
+	assert.Equal(t, core.ErrRateLimited, err.Type)
+	assert.Equal(t, "rate limit of 100 requests exceeded", err.Error())
+	assert.NotEmpty(t, err.PublicMessage)
+
+	limit, remaining, reset, ok := err.RateLimit()
+	assert.True(t, ok)
+	assert.Equal(t, 100, limit)
+	assert.Equal(t, 0, remaining)
+	assert.Equal(t, resetAt, reset)
+
+	frames := err.StackTrace(false)
+	assert.Equal(t, "github.com/iMohamedSheta/xerr/core_test.TestRateLimited", frames[0].Function)
+}
+
+// TestRateLimitOnOtherErrors ensures RateLimit reports false for other errors
+func TestRateLimitOnOtherErrors(t *testing.T) {
+	_, _, _, ok := core.New("boom", core.ErrUnknown, nil).RateLimit()
+	assert.False(t, ok)
+
+	var nilErr *core.XErr
+	_, _, _, ok = nilErr.RateLimit()
+	assert.False(t, ok)
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Schema describes the details an XErr of a given type must carry
+type Schema struct {
+	Required []string                // Keys that must be present
+	Types    map[string]reflect.Type // Types the values of these keys must be assignable to, when present
+}
+
+var (
+	schemasMu sync.RWMutex
+	schemas   = map[ErrorType]Schema{}
+
+	// onViolation is the validation hook, nil when validation is disabled
+	onViolation func(e *XErr, err error)
+)
+
+// RegisterSchema sets the details schema of an ErrorType
+func RegisterSchema(t ErrorType, s Schema) {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	schemas[t] = s
+}
+
+// UnregisterSchema removes the details schema of an ErrorType
+func UnregisterSchema(t ErrorType) {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	delete(schemas, t)
+}
+
+// SetSchemaValidation enables details validation, meant for development.
+// Details are checked by WithDetails and RateLimited, and by CheckDetails when the
+// error is handled, so errors never given their required details are caught too.
+// fn is called with the error and its violations whenever the details don't match
+// the registered schema, e.g. to log or panic. A nil fn disables validation.
+// The violations of the latest check are available from (*XErr).DetailsError.
+func SetSchemaValidation(fn func(e *XErr, err error)) {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	onViolation = fn
+}
+
+// ValidateDetails checks details against the schema registered for t.
+// It returns nil when they match or when t has no schema.
+func ValidateDetails(t ErrorType, details map[string]any) error {
+	schemasMu.RLock()
+	s, ok := schemas[t]
+	schemasMu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	var errs []error
+	for _, key := range s.Required {
+		if _, ok := details[key]; !ok {
+			errs = append(errs, fmt.Errorf("missing required detail %q", key))
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(s.Types)) {
+		value, ok := details[key]
+		if !ok {
+			continue
+		}
+		want := s.Types[key]
+		if value == nil || !reflect.TypeOf(value).AssignableTo(want) {
+			errs = append(errs, fmt.Errorf("detail %q is %T, want %s", key, value, want))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DetailsError returns the schema violations found by the latest check,
+// or nil when they matched, weren't checked yet or validation was disabled
+func (e *XErr) DetailsError() error {
+	return e.detailsErr
+}
+
+// CheckDetails validates the details of e unless they were already checked, and
+// returns the violations. Error handlers call it so errors created without
+// details and never given any by WithDetails are validated too.
+func (e *XErr) CheckDetails() error {
+	if !e.checked {
+		e.validateDetails()
+	}
+	return e.detailsErr
+}
+
+// validateDetails records the schema violations of e when validation is enabled
+func (e *XErr) validateDetails() {
+	schemasMu.RLock()
+	fn := onViolation
+	schemasMu.RUnlock()
+	if fn == nil {
+		return
+	}
+
+	e.checked = true
+	e.detailsErr = ValidateDetails(e.Type, e.Details)
+	if e.detailsErr != nil {
+		fn(e, e.detailsErr)
+	}
+}
@@ -0,0 +1,108 @@
+package core_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iMohamedSheta/xerr/core"
+)
+
+const errPaymentFailed core.ErrorType = 1000
+
+func registerPaymentSchema(t *testing.T) {
+	core.RegisterSchema(errPaymentFailed, core.Schema{
+		Required: []string{"order_id"},
+		Types:    map[string]reflect.Type{"order_id": reflect.TypeFor[string](), "amount": reflect.TypeFor[float64]()},
+	})
+	t.Cleanup(func() { core.UnregisterSchema(errPaymentFailed) })
+}
+
+// TestValidateDetails ensures missing keys and mistyped values are reported
+func TestValidateDetails(t *testing.T) {
+	registerPaymentSchema(t)
+
+	assert.NoError(t, core.ValidateDetails(errPaymentFailed, map[string]any{"order_id": "o-1", "amount": 9.5}))
+	assert.NoError(t, core.ValidateDetails(core.ErrUnknown, nil), "types without a schema always match")
+
+	err := core.ValidateDetails(errPaymentFailed, map[string]any{"amount": 9})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing required detail "order_id"`)
+	assert.Contains(t, err.Error(), `detail "amount" is int, want float64`)
+}
+
+// TestWithDetailsValidation ensures WithDetails reports mismatching details only
+func TestWithDetailsValidation(t *testing.T) {
+	registerPaymentSchema(t)
+
+	xe := core.New("charge failed", errPaymentFailed, nil).WithDetails(map[string]any{})
+	assert.NoError(t, xe.DetailsError(), "validation is disabled by default")
+
+	var violations []error
+	core.SetSchemaValidation(func(_ *core.XErr, err error) {
+		violations = append(violations, err)
+	})
+	defer core.SetSchemaValidation(nil)
+
+	xe = core.New("charge failed", errPaymentFailed, nil).WithDetails(map[string]any{"order_id": "o-1"})
+	assert.NoError(t, xe.CheckDetails())
+	assert.Empty(t, violations, "Correctly built errors must not be reported")
+
+	xe = core.New("charge failed", errPaymentFailed, nil).WithDetails(map[string]any{"amount": 1})
+	assert.ErrorContains(t, xe.DetailsError(), `detail "amount" is int`)
+	assert.Len(t, violations, 1)
+
+	xe.CheckDetails()
+	assert.Len(t, violations, 1, "Checked details are not reported again")
+}
+
+// TestCheckDetailsValidatesErrorsWithoutDetails ensures errors never given their details are caught
+func TestCheckDetailsValidatesErrorsWithoutDetails(t *testing.T) {
+	registerPaymentSchema(t)
+
+	var calls int
+	core.SetSchemaValidation(func(xe *core.XErr, err error) {
+		calls++
+		assert.Equal(t, "pay failed", xe.Message)
+		assert.ErrorContains(t, err, `missing required detail "order_id"`)
+	})
+	defer core.SetSchemaValidation(nil)
+
+	xe := core.New("pay failed", errPaymentFailed, errors.New("card declined"))
+	assert.Zero(t, calls, "New must not validate details that WithDetails may still set")
+	assert.NoError(t, xe.DetailsError())
+
+	assert.Error(t, xe.CheckDetails())
+	assert.Equal(t, 1, calls)
+
+	assert.NoError(t, core.New("other failure", core.ErrUnknown, nil).CheckDetails())
+	assert.Equal(t, 1, calls, "Types without a schema are not reported")
+}
+
+// TestRateLimitedValidatesDetails ensures errors built with their details are checked at creation
+func TestRateLimitedValidatesDetails(t *testing.T) {
+	core.RegisterSchema(core.ErrRateLimited, core.Schema{Required: []string{"window"}})
+	defer core.UnregisterSchema(core.ErrRateLimited)
+
+	var calls int
+	core.SetSchemaValidation(func(*core.XErr, error) { calls++ })
+	defer core.SetSchemaValidation(nil)
+
+	xe := core.RateLimited(10, 0, time.Now())
+	assert.ErrorContains(t, xe.DetailsError(), `missing required detail "window"`)
+	assert.Equal(t, 1, calls)
+}
+
+// TestUnregisterSchema ensures types without a schema match again once it is removed
+func TestUnregisterSchema(t *testing.T) {
+	const errRefund core.ErrorType = 1001
+	core.RegisterSchema(errRefund, core.Schema{Required: []string{"refund_id"}})
+	assert.Error(t, core.ValidateDetails(errRefund, nil))
+
+	core.UnregisterSchema(errRefund)
+	assert.NoError(t, core.ValidateDetails(errRefund, nil))
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CodeSnippet extracts a few lines around the error line
+func CodeSnippet(file string, line int) string {
+	data, err := readSource(file)
+	if err != nil {
+		return "Could not read source file"
+	}
+
+	return FormatSnippet(data, line)
+}
+
+// FormatSnippet renders the lines around line from the given source
+func FormatSnippet(data []byte, line int) string {
+	lines := strings.Split(string(data), "\n")
+	start := line - 15
+	if start < 0 {
+		start = 0
+	}
+	end := line + 20
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		prefix := "   "
+		if i+1 == line {
+			prefix = ">> "
+		}
+		fmt.Fprintf(&b, "%s%4d | %s\n", prefix, i+1, lines[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,35 @@
+package xerr_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iMohamedSheta/xerr"
+	"github.com/iMohamedSheta/xerr/core"
+)
+
+// TestCoreAliases ensures errors created through xerr and core are interchangeable
+func TestCoreAliases(t *testing.T) {
+	var err error = xerr.New("not found", xerr.ErrUnknown, nil)
+
+	var ce *core.XErr
+	assert.True(t, errors.As(err, &ce))
+	assert.Equal(t, core.ErrUnknown, ce.Type)
+}
+
+// TestNewStackStartsAtCaller ensures the alias doesn't add a frame to the captured stack
+func TestNewStackStartsAtCaller(t *testing.T) {
+	frames := xerr.New("boom", xerr.ErrUnknown, nil).StackTrace(false)
+	assert.NotEmpty(t, frames)
+	assert.Equal(t, "github.com/iMohamedSheta/xerr_test.TestNewStackStartsAtCaller", frames[0].Function)
+}
+
+// TestRateLimitedStackStartsAtCaller ensures the wrapper doesn't add a frame to the captured stack
+func TestRateLimitedStackStartsAtCaller(t *testing.T) {
+	frames := xerr.RateLimited(10, 0, time.Now()).StackTrace(false)
+	assert.NotEmpty(t, frames)
+	assert.Equal(t, "github.com/iMohamedSheta/xerr_test.TestRateLimitedStackStartsAtCaller", frames[0].Function)
+}
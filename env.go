@@ -0,0 +1,87 @@
+package xerr
+
+import (
+	"io"
+	"maps"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// DefaultEnvKeys are the environment variables recorded when Config.EnvKeys is nil
+var DefaultEnvKeys = []string{"GOMAXPROCS", "GOGC", "GOMEMLIMIT", "GODEBUG", "GOFLAGS", "CGO_ENABLED", "TZ", "LANG"}
+
+// EnvSnapshot describes the runtime environment an error happened in
+type EnvSnapshot struct {
+	GoVersion string            `json:"go_version"`
+	OS        string            `json:"os"`
+	Arch      string            `json:"arch"`
+	Vars      map[string]string `json:"vars,omitempty"` // Recorded environment variables, unset ones are omitted
+}
+
+// CurrentEnv captures the environment of the running process.
+// Only the given environment variables are recorded, never the whole environment.
+func CurrentEnv(keys []string) EnvSnapshot {
+	env := EnvSnapshot{
+		GoVersion: strings.TrimPrefix(runtime.Version(), "go"),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		Vars:      make(map[string]string, len(keys)),
+	}
+	for _, key := range keys {
+		if value, ok := os.LookupEnv(key); ok {
+			env.Vars[key] = value
+		}
+	}
+	return env
+}
+
+// EnvDiff is a value that differs between two environments
+type EnvDiff struct {
+	Key      string
+	Recorded string
+	Local    string
+}
+
+// DiffEnv compares the environment recorded with an error to a local one,
+// e.g. to explain why an error doesn't reproduce on a developer machine.
+// A variable set on only one side is reported with an empty value on the other.
+func DiffEnv(recorded, local EnvSnapshot) []EnvDiff {
+	var diffs []EnvDiff
+	add := func(key, a, b string) {
+		if a != b {
+			diffs = append(diffs, EnvDiff{Key: key, Recorded: a, Local: b})
+		}
+	}
+
+	add("go_version", recorded.GoVersion, local.GoVersion)
+	add("os", recorded.OS, local.OS)
+	add("arch", recorded.Arch, local.Arch)
+
+	keys := slices.Sorted(maps.Keys(recorded.Vars))
+	for key := range local.Vars {
+		if _, ok := recorded.Vars[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		add(key, recorded.Vars[key], local.Vars[key])
+	}
+	return diffs
+}
+
+// DiffEnv compares the environment the error happened in to local
+func (d *ErrorData) DiffEnv(local EnvSnapshot) []EnvDiff {
+	return DiffEnv(d.Env, local)
+}
+
+// RenderReport renders a stored report, e.g. one a reporter received and encoded as
+// JSON, with the configured renderer. The environment it was recorded in is compared
+// to the local one (using Config.EnvKeys) and the differences are highlighted.
+func (eh *ErrorHandler) RenderReport(w io.Writer, data *ErrorData) error {
+	report := *data
+	report.EnvDiff = report.DiffEnv(CurrentEnv(eh.envKeys()))
+	return eh.renderer.Render(w, &report)
+}
@@ -0,0 +1,84 @@
+package xerr
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrentEnvRecordsOnlyGivenKeys(t *testing.T) {
+	t.Setenv("XERR_TEST_TZ", "Africa/Cairo")
+	t.Setenv("XERR_TEST_SECRET", "hunter2")
+
+	env := CurrentEnv([]string{"XERR_TEST_TZ", "XERR_TEST_UNSET"})
+	assert.Equal(t, runtime.GOOS, env.OS)
+	assert.Equal(t, runtime.GOARCH, env.Arch)
+	assert.NotEmpty(t, env.GoVersion)
+	assert.Equal(t, map[string]string{"XERR_TEST_TZ": "Africa/Cairo"}, env.Vars)
+}
+
+func TestDiffEnv(t *testing.T) {
+	recorded := EnvSnapshot{GoVersion: "1.24.1", OS: "linux", Arch: "amd64", Vars: map[string]string{"TZ": "UTC", "GOGC": "50", "LANG": "C"}}
+	local := EnvSnapshot{GoVersion: "1.24.1", OS: "darwin", Arch: "amd64", Vars: map[string]string{"TZ": "UTC", "LANG": "en_US", "GODEBUG": "x=1"}}
+
+	assert.Equal(t, []EnvDiff{
+		{Key: "os", Recorded: "linux", Local: "darwin"},
+		{Key: "GODEBUG", Recorded: "", Local: "x=1"},
+		{Key: "GOGC", Recorded: "50", Local: ""},
+		{Key: "LANG", Recorded: "C", Local: "en_US"},
+	}, DiffEnv(recorded, local))
+	assert.Empty(t, DiffEnv(recorded, recorded))
+}
+
+func TestHandleErrorRecordsEnv(t *testing.T) {
+	t.Setenv("XERR_TEST_REGION", "eu-west-1")
+	eh := NewErrorHandler(&Config{EnvKeys: []string{"XERR_TEST_REGION"}})
+
+	w := httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/", nil), "boom")
+	assert.Contains(t, w.Body.String(), "XERR_TEST_REGION")
+	assert.Contains(t, w.Body.String(), "eu-west-1")
+
+	data := eh.errorData(nil, "boom", AllFields)
+	assert.Equal(t, "eu-west-1", data.Env.Vars["XERR_TEST_REGION"])
+	assert.Equal(t, []EnvDiff{{Key: "XERR_TEST_REGION", Recorded: "eu-west-1", Local: "us-east-1"}},
+		data.DiffEnv(EnvSnapshot{GoVersion: data.Env.GoVersion, OS: data.Env.OS, Arch: data.Env.Arch, Vars: map[string]string{"XERR_TEST_REGION": "us-east-1"}}))
+}
+
+func TestEnvIsEncodedOnce(t *testing.T) {
+	data := NewErrorHandler(nil).errorData(nil, "boom", AllFields)
+	assert.Equal(t, data.Env.GoVersion, data.GoVersion, "The deprecated fields mirror Env")
+
+	body, err := json.Marshal(data)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), `"GoVersion"`)
+	assert.Contains(t, string(body), `"go_version"`)
+}
+
+func TestRenderReportHighlightsEnvDiff(t *testing.T) {
+	t.Setenv("XERR_TEST_REGION", "eu-west-1")
+	eh := NewErrorHandler(&Config{EnvKeys: []string{"XERR_TEST_REGION"}, MaxFrames: 10})
+	body, err := json.Marshal(eh.errorData(nil, "boom", AllFields))
+	require.NoError(t, err)
+
+	// The report is rendered later on a machine in another region
+	t.Setenv("XERR_TEST_REGION", "us-east-1")
+	var report ErrorData
+	require.NoError(t, json.Unmarshal(body, &report))
+
+	var page bytes.Buffer
+	require.NoError(t, eh.RenderReport(&page, &report))
+	assert.Contains(t, page.String(), "Differs locally:")
+	assert.Contains(t, page.String(), "XERR_TEST_REGION: recorded eu-west-1, local us-east-1")
+	assert.Nil(t, report.EnvDiff, "The stored report is not modified")
+
+	page.Reset()
+	require.NoError(t, eh.RenderReport(&page, eh.errorData(nil, "boom", AllFields)))
+	assert.NotContains(t, page.String(), "Differs locally:")
+}
@@ -0,0 +1,28 @@
+//go:build !js
+
+package xerr
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+)
+
+// parseTemplateFile parses a custom error page template from disk
+func parseTemplateFile(path string, funcs template.FuncMap) (*template.Template, error) {
+	return template.New(execTemplate).Funcs(funcs).ParseFiles(path)
+}
+
+// LoadConfig reads a JSON configuration file on top of DefaultConfig
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return config, nil
+}
@@ -0,0 +1,16 @@
+//go:build js
+
+package xerr
+
+import (
+	"errors"
+	"html/template"
+)
+
+// errNoFilesystem is returned for operations that need files on js/wasm
+var errNoFilesystem = errors.New("xerr: filesystem access is not available on js/wasm")
+
+// parseTemplateFile always fails as custom templates can't be read from disk in the browser
+func parseTemplateFile(string, template.FuncMap) (*template.Template, error) {
+	return nil, errNoFilesystem
+}
@@ -0,0 +1,32 @@
+//go:build !js
+
+package xerr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "xerr.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"maxFrames": 10, "reporters": [{"name": "test-recording"}]}`), 0644))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, 10, cfg.MaxFrames)
+	assert.Equal(t, "test-recording", cfg.ReporterConfigs[0].Name)
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	_, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "xerr.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{`), 0644))
+	_, err = LoadConfig(path)
+	assert.ErrorContains(t, err, "failed to parse config")
+}
@@ -0,0 +1,45 @@
+package xerr
+
+import (
+	"context"
+	"net/http"
+)
+
+// Handler is the interface implemented by ErrorHandler.
+// Applications can depend on it to mock error handling or swap in NopHandler in tests.
+type Handler interface {
+	HandleError(w http.ResponseWriter, r *http.Request, err interface{})
+	Middleware(next http.Handler) http.Handler
+	Report(err interface{})
+	Flush(ctx context.Context) error
+}
+
+var (
+	_ Handler = (*ErrorHandler)(nil)
+	_ Handler = NopHandler{}
+)
+
+// NopHandler is a Handler that doesn't render, report or recover anything.
+// HandleError only writes the mapped status code, and Middleware returns next
+// unchanged so panics reach the caller.
+type NopHandler struct{}
+
+// HandleError writes the status code mapped to err with its status text
+func (NopHandler) HandleError(w http.ResponseWriter, r *http.Request, err interface{}) {
+	e, _ := err.(error)
+	status := StatusCode(e)
+	http.Error(w, http.StatusText(status), status)
+}
+
+// Middleware returns next unchanged
+func (NopHandler) Middleware(next http.Handler) http.Handler {
+	return next
+}
+
+// Report does nothing
+func (NopHandler) Report(interface{}) {}
+
+// Flush does nothing
+func (NopHandler) Flush(context.Context) error {
+	return nil
+}
@@ -0,0 +1,32 @@
+package xerr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNopHandler(t *testing.T) {
+	SetStatusCode(errNotFound, http.StatusNotFound)
+	var h Handler = NopHandler{}
+
+	w := httptest.NewRecorder()
+	h.HandleError(w, httptest.NewRequest(http.MethodGet, "/", nil), New("missing", errNotFound, nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "Not Found\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	h.HandleError(w, nil, "panic value")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { panic("not recovered") })
+	assert.Panics(t, func() {
+		h.Middleware(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+
+	h.Report("ignored")
+	assert.NoError(t, h.Flush(context.Background()))
+}
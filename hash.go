@@ -0,0 +1,45 @@
+package xerr
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// hashSnippets sets the SnippetHash of every frame, so a snippet can be identified
+// even in projections that drop it
+func hashSnippets(frames []Frame) {
+	for i := range frames {
+		frames[i].SnippetHash = hashString(frames[i].Snippet)
+	}
+}
+
+// Sign returns the HMAC-SHA256 (hex) of the JSON encoding of d under key.
+// The signature is kept out of the data (Signature isn't encoded), so it must be
+// stored next to the report; without the key it can't be recomputed after an edit.
+func (d *ErrorData) Sign(key []byte) string {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return ""
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature matches the data under key, see Sign
+func (d *ErrorData) Verify(key []byte, signature string) bool {
+	want, err := hex.DecodeString(signature)
+	if err != nil || len(key) == 0 {
+		return false
+	}
+	got, err := hex.DecodeString(d.Sign(key))
+	return err == nil && hmac.Equal(got, want)
+}
+
+// hashString returns the hex encoded SHA-256 of s
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,77 @@
+package xerr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSigningKey = []byte("test-signing-key")
+
+func TestErrorDataSnippetHashes(t *testing.T) {
+	eh := NewErrorHandler(nil)
+	data := eh.errorData(httptest.NewRequest(http.MethodGet, "/", nil), New("boom", ErrUnknown, nil), AllFields)
+
+	require.NotEmpty(t, data.Frames)
+	assert.Equal(t, hashString(data.Frames[0].Snippet), data.Frames[0].SnippetHash)
+	assert.Equal(t, data.Frames[0].SnippetHash, data.Groups[0].Frames[0].SnippetHash)
+}
+
+func TestErrorDataSignature(t *testing.T) {
+	eh := NewErrorHandler(nil)
+	data := eh.errorData(httptest.NewRequest(http.MethodGet, "/", nil), New("boom", ErrUnknown, nil), AllFields)
+	signature := data.Sign(testSigningKey)
+	assert.Len(t, signature, 64)
+	assert.True(t, data.Verify(testSigningKey, signature))
+
+	// A stored report still verifies after a JSON round trip
+	encoded, err := json.Marshal(data)
+	require.NoError(t, err)
+	var stored ErrorData
+	require.NoError(t, json.Unmarshal(encoded, &stored))
+	assert.True(t, stored.Verify(testSigningKey, signature))
+
+	tampered := stored
+	tampered.Error = "something else"
+	assert.False(t, tampered.Verify(testSigningKey, signature))
+	assert.False(t, tampered.Verify(testSigningKey, tampered.Sign([]byte("attacker key"))), "Re-signing needs the key")
+
+	stored.Frames[0].Snippet += "// injected"
+	assert.False(t, stored.Verify(testSigningKey, signature))
+}
+
+func TestVerifyRejectsMissingKeyOrSignature(t *testing.T) {
+	data := &ErrorData{Error: "x"}
+	assert.False(t, data.Verify(testSigningKey, ""))
+	assert.False(t, data.Verify(testSigningKey, "not hex"))
+	assert.False(t, data.Verify(nil, data.Sign(nil)))
+}
+
+func TestReportersReceiveVerifiableProjections(t *testing.T) {
+	full := &recordingReporter{}
+	slim := &projectingReporter{}
+	eh := NewErrorHandler(&Config{MaxFrames: 10, ShowSourceCode: true, SigningKey: testSigningKey, Reporters: []Reporter{full, slim}})
+
+	eh.Report(New("boom", ErrUnknown, nil))
+	require.NoError(t, eh.Flush(context.Background()))
+
+	for _, data := range []*ErrorData{full.received()[0], slim.received()[0]} {
+		assert.NotEmpty(t, data.Signature)
+		assert.True(t, data.Verify(testSigningKey, data.Signature))
+	}
+	assert.NotEqual(t, full.received()[0].Signature, slim.received()[0].Signature)
+}
+
+func TestReportersAreNotSignedWithoutKey(t *testing.T) {
+	rr := &recordingReporter{}
+	eh := NewErrorHandler(&Config{Reporters: []Reporter{rr}})
+
+	eh.Report(New("boom", ErrUnknown, nil))
+	require.NoError(t, eh.Flush(context.Background()))
+	assert.Empty(t, rr.received()[0].Signature)
+}
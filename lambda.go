@@ -0,0 +1,89 @@
+package xerr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+)
+
+// APIGatewayResponse is an API Gateway proxy response.
+// It encodes like events.APIGatewayProxyResponse from aws-lambda-go.
+type APIGatewayResponse struct {
+	StatusCode      int               `json:"statusCode"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Body            string            `json:"body"`
+	IsBase64Encoded bool              `json:"isBase64Encoded,omitempty"`
+}
+
+// LambdaInvoker matches lambda.Handler from aws-lambda-go
+type LambdaInvoker interface {
+	Invoke(ctx context.Context, payload []byte) ([]byte, error)
+}
+
+// LambdaHandler wraps an API Gateway lambda function.
+// Panics and returned errors are reported and turned into JSON error responses using
+// the status mapping. Reporters are flushed before every invocation returns, including
+// successful ones, so reports queued by fn are delivered before the function freezes.
+func LambdaHandler[E any](eh *ErrorHandler, fn func(context.Context, E) (APIGatewayResponse, error)) func(context.Context, E) (APIGatewayResponse, error) {
+	return func(ctx context.Context, event E) (resp APIGatewayResponse, err error) {
+		defer eh.flushInvocation(ctx)
+		defer func() {
+			if rec := recover(); rec != nil {
+				resp, err = eh.lambdaError(rec), nil
+			}
+		}()
+
+		resp, err = fn(ctx, event)
+		if err != nil {
+			return eh.lambdaError(err), nil
+		}
+		return resp, nil
+	}
+}
+
+// WrapLambdaInvoker wraps a lambda.Handler the same way LambdaHandler wraps a function
+func WrapLambdaInvoker(eh *ErrorHandler, h LambdaInvoker) LambdaInvoker {
+	return lambdaInvoker{eh: eh, next: h}
+}
+
+type lambdaInvoker struct {
+	eh   *ErrorHandler
+	next LambdaInvoker
+}
+
+func (li lambdaInvoker) Invoke(ctx context.Context, payload []byte) (out []byte, err error) {
+	defer li.eh.flushInvocation(ctx)
+	defer func() {
+		if rec := recover(); rec != nil {
+			out, err = json.Marshal(li.eh.lambdaError(rec))
+		}
+	}()
+
+	out, err = li.next.Invoke(ctx, payload)
+	if err != nil {
+		return json.Marshal(li.eh.lambdaError(err))
+	}
+	return out, nil
+}
+
+// flushInvocation waits for the reports of an invocation before the function freezes
+func (eh *ErrorHandler) flushInvocation(ctx context.Context) {
+	if err := eh.Flush(ctx); err != nil {
+		eh.reportError(fmt.Errorf("flushing reporters: %w", err))
+	}
+}
+
+// lambdaError reports err and builds the error response
+func (eh *ErrorHandler) lambdaError(err interface{}) APIGatewayResponse {
+	eh.Report(err)
+
+	status, body := PublicResponse(err)
+	headers := map[string]string{"Content-Type": "application/json"}
+	maps.Copy(headers, rateLimitHeaders(err))
+	return APIGatewayResponse{
+		StatusCode: status,
+		Headers:    headers,
+		Body:       string(body),
+	}
+}
@@ -0,0 +1,124 @@
+package xerr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderEvent struct {
+	ID string `json:"id"`
+}
+
+func TestLambdaHandler(t *testing.T) {
+	SetStatusCode(errNotFound, http.StatusNotFound)
+	rr := &recordingReporter{}
+	eh := NewErrorHandler(&Config{Reporters: []Reporter{rr}})
+
+	h := LambdaHandler(eh, func(ctx context.Context, e orderEvent) (APIGatewayResponse, error) {
+		switch e.ID {
+		case "missing":
+			return APIGatewayResponse{}, New("order missing", errNotFound, nil).WithPublicMessage("Order not found")
+		case "panic":
+			panic("nil order")
+		}
+		return APIGatewayResponse{StatusCode: http.StatusOK, Body: "ok"}, nil
+	})
+
+	resp, err := h(context.Background(), orderEvent{ID: "1"})
+	assert.NoError(t, err)
+	assert.Equal(t, APIGatewayResponse{StatusCode: http.StatusOK, Body: "ok"}, resp)
+	assert.Empty(t, rr.received())
+
+	resp, err = h(context.Background(), orderEvent{ID: "missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Headers["Content-Type"])
+	assert.JSONEq(t, `{"status": 404, "type": 5000, "message": "Order not found"}`, resp.Body)
+	assert.Len(t, rr.received(), 1, "Reports must be flushed before returning")
+
+	resp, err = h(context.Background(), orderEvent{ID: "panic"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Len(t, rr.received(), 2)
+	assert.Equal(t, "nil order", rr.received()[1].Error)
+}
+
+type invokerFunc func(ctx context.Context, payload []byte) ([]byte, error)
+
+func (f invokerFunc) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	return f(ctx, payload)
+}
+
+func TestWrapLambdaInvoker(t *testing.T) {
+	rr := &recordingReporter{}
+	eh := NewErrorHandler(&Config{Reporters: []Reporter{rr}})
+
+	h := WrapLambdaInvoker(eh, invokerFunc(func(ctx context.Context, payload []byte) ([]byte, error) {
+		switch string(payload) {
+		case "fail":
+			return nil, errors.New("db down")
+		case "panic":
+			panic("boom")
+		}
+		return payload, nil
+	}))
+
+	out, err := h.Invoke(context.Background(), []byte("echo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "echo", string(out))
+
+	for _, payload := range []string{"fail", "panic"} {
+		out, err = h.Invoke(context.Background(), []byte(payload))
+		assert.NoError(t, err)
+
+		var resp APIGatewayResponse
+		require.NoError(t, json.Unmarshal(out, &resp))
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.JSONEq(t, `{"status": 500, "type": 0, "message": "Internal Server Error"}`, resp.Body)
+	}
+	assert.Len(t, rr.received(), 2)
+}
+
+func TestLambdaHandlerFlushesSuccessfulInvocations(t *testing.T) {
+	rr := &recordingReporter{delay: 20 * time.Millisecond}
+	eh := NewErrorHandler(&Config{Reporters: []Reporter{rr}})
+
+	h := LambdaHandler(eh, func(ctx context.Context, e orderEvent) (APIGatewayResponse, error) {
+		eh.Report(errors.New("cache miss"))
+		return APIGatewayResponse{StatusCode: http.StatusOK}, nil
+	})
+	_, err := h(context.Background(), orderEvent{})
+	assert.NoError(t, err)
+	assert.Len(t, rr.received(), 1, "Reports queued by a successful invocation must be flushed")
+
+	inv := WrapLambdaInvoker(eh, invokerFunc(func(ctx context.Context, payload []byte) ([]byte, error) {
+		eh.Report(errors.New("cache miss"))
+		return payload, nil
+	}))
+	_, err = inv.Invoke(context.Background(), []byte("echo"))
+	assert.NoError(t, err)
+	assert.Len(t, rr.received(), 2)
+}
+
+func TestLambdaFlushErrorsAreReported(t *testing.T) {
+	var failures []error
+	rr := &recordingReporter{delay: time.Second}
+	eh := NewErrorHandler(&Config{Reporters: []Reporter{rr}, OnReportError: func(err error) { failures = append(failures, err) }})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	h := LambdaHandler(eh, func(context.Context, orderEvent) (APIGatewayResponse, error) {
+		return APIGatewayResponse{}, errors.New("db down")
+	})
+	_, err := h(ctx, orderEvent{})
+	assert.NoError(t, err)
+	assert.Len(t, failures, 1)
+	assert.ErrorIs(t, failures[0], context.DeadlineExceeded)
+}
@@ -0,0 +1,32 @@
+package xerr
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// rateLimitHeaders returns the RateLimit-* and Retry-After headers for a RateLimited error
+func rateLimitHeaders(err interface{}) map[string]string {
+	e, ok := err.(error)
+	if !ok {
+		return nil
+	}
+
+	xe, ok := As(e)
+	if !ok {
+		return nil
+	}
+	limit, remaining, resetAt, ok := xe.RateLimit()
+	if !ok {
+		return nil
+	}
+
+	reset := strconv.Itoa(max(0, int(math.Ceil(time.Until(resetAt).Seconds()))))
+	return map[string]string{
+		"RateLimit-Limit":     strconv.Itoa(limit),
+		"RateLimit-Remaining": strconv.Itoa(remaining),
+		"RateLimit-Reset":     reset,
+		"Retry-After":         reset,
+	}
+}
@@ -0,0 +1,58 @@
+package xerr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleErrorRateLimited(t *testing.T) {
+	resetAt := time.Now().Add(30 * time.Second)
+	eh := NewErrorHandler(&Config{PublicJSON: true})
+
+	w := httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/api", nil), RateLimited(100, 0, resetAt))
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "100", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{
+		"status": 429,
+		"type": -1,
+		"message": "Too many requests, please retry later",
+		"details": {"limit": 100, "remaining": 0, "reset_at": "`+resetAt.UTC().Format(time.RFC3339Nano)+`"}
+	}`, w.Body.String())
+}
+
+func TestHandleErrorRateLimitedPage(t *testing.T) {
+	eh := NewErrorHandler(nil)
+	w := httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/api", nil), RateLimited(10, 0, time.Now().Add(-time.Second)))
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("Retry-After"), "Retry-After must not be negative")
+}
+
+func TestLambdaHandlerRateLimited(t *testing.T) {
+	eh := NewErrorHandler(nil)
+	h := LambdaHandler(eh, func(ctx context.Context, e struct{}) (APIGatewayResponse, error) {
+		return APIGatewayResponse{}, RateLimited(5, 0, time.Now().Add(time.Minute))
+	})
+
+	resp, err := h(context.Background(), struct{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "5", resp.Headers["RateLimit-Limit"])
+	assert.Equal(t, "application/json", resp.Headers["Content-Type"])
+}
+
+func TestRateLimitHeadersIgnoresOtherErrors(t *testing.T) {
+	assert.Nil(t, rateLimitHeaders("panic"))
+	assert.Nil(t, rateLimitHeaders(New("boom", ErrUnknown, nil)))
+}
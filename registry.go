@@ -0,0 +1,101 @@
+package xerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ReporterFactory builds a Reporter from the options given in the configuration
+type ReporterFactory func(options map[string]any) (Reporter, error)
+
+// ReporterConfig selects a registered reporter by name
+type ReporterConfig struct {
+	Name    string         `json:"name"`
+	Options map[string]any `json:"options"`
+}
+
+var (
+	reporterFactoriesMu sync.RWMutex
+	reporterFactories   = map[string]ReporterFactory{}
+)
+
+// RegisterReporterFactory makes a reporter available by name to ReporterConfig.
+// It is meant to be called from the init function of the package providing the reporter
+// and panics if the name is registered twice or factory is nil.
+func RegisterReporterFactory(name string, factory ReporterFactory) {
+	reporterFactoriesMu.Lock()
+	defer reporterFactoriesMu.Unlock()
+
+	if factory == nil {
+		panic("xerr: RegisterReporterFactory factory is nil")
+	}
+	if _, dup := reporterFactories[name]; dup {
+		panic("xerr: RegisterReporterFactory called twice for " + name)
+	}
+	reporterFactories[name] = factory
+}
+
+// ReporterFactories returns the sorted names of the registered reporter factories
+func ReporterFactories() []string {
+	reporterFactoriesMu.RLock()
+	defer reporterFactoriesMu.RUnlock()
+	return slices.Sorted(maps.Keys(reporterFactories))
+}
+
+// NewReporter builds the reporter registered under name
+func NewReporter(name string, options map[string]any) (Reporter, error) {
+	reporterFactoriesMu.RLock()
+	factory, ok := reporterFactories[name]
+	reporterFactoriesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown reporter %q (forgotten import?)", name)
+	}
+
+	reporter, err := factory(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reporter %q: %w", name, err)
+	}
+	return reporter, nil
+}
+
+// Duration is a time.Duration that is written as a string such as "500ms" in JSON.
+// Integer nanoseconds are accepted too.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case float64:
+		*d = Duration(v)
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*d = Duration(parsed)
+	default:
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	return nil
+}
+
+// ParseConfig reads a JSON configuration on top of DefaultConfig
+func ParseConfig(data []byte) (*Config, error) {
+	config := DefaultConfig()
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
@@ -0,0 +1,114 @@
+package xerr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	RegisterReporterFactory("test-recording", func(options map[string]any) (Reporter, error) {
+		if options["fail"] == true {
+			return nil, errors.New("missing topic")
+		}
+		return &recordingReporter{}, nil
+	})
+}
+
+func TestRegisterReporterFactoryPanics(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterReporterFactory("test-recording", func(map[string]any) (Reporter, error) { return nil, nil })
+	})
+	assert.Panics(t, func() { RegisterReporterFactory("test-nil", nil) })
+	assert.Contains(t, ReporterFactories(), "test-recording")
+}
+
+func TestNewReporter(t *testing.T) {
+	r, err := NewReporter("test-recording", nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &recordingReporter{}, r)
+
+	_, err = NewReporter("test-recording", map[string]any{"fail": true})
+	assert.ErrorContains(t, err, `failed to create reporter "test-recording": missing topic`)
+
+	_, err = NewReporter("kafka", nil)
+	assert.ErrorContains(t, err, `unknown reporter "kafka"`)
+}
+
+func TestParseConfigBuildsReporters(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{
+		"maxFrames": 10,
+		"debugMode": false,
+		"suppress": [{"message": "^bot"}],
+		"reporters": [{"name": "test-recording", "options": {"topic": "errors"}}]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, 10, cfg.MaxFrames)
+	assert.False(t, cfg.DebugMode)
+	assert.True(t, cfg.ShowSourceCode, "Unset fields should keep their defaults")
+	assert.Equal(t, "^bot", cfg.Suppress[0].Message)
+	assert.Equal(t, []ReporterConfig{{Name: "test-recording", Options: map[string]any{"topic": "errors"}}}, cfg.ReporterConfigs)
+
+	eh := NewErrorHandler(cfg)
+	require.Len(t, eh.reporters, 1)
+	eh.Report("boom")
+	assert.NoError(t, eh.Flush(context.Background()))
+	assert.Len(t, eh.reporters[0].(*recordingReporter).received(), 1)
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	_, err := ParseConfig([]byte(`{`))
+	assert.Error(t, err)
+
+	assert.Panics(t, func() {
+		NewErrorHandler(&Config{ReporterConfigs: []ReporterConfig{{Name: "kafka"}}})
+	})
+}
+
+func TestParseConfigCriticalTimeout(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{"criticalTimeout": "500ms"}`))
+	require.NoError(t, err)
+	assert.Equal(t, Duration(500*time.Millisecond), cfg.CriticalTimeout)
+
+	cfg, err = ParseConfig([]byte(`{"criticalTimeout": 1000000}`))
+	require.NoError(t, err)
+	assert.Equal(t, Duration(time.Millisecond), cfg.CriticalTimeout)
+
+	_, err = ParseConfig([]byte(`{"criticalTimeout": "soon"}`))
+	assert.Error(t, err)
+	_, err = ParseConfig([]byte(`{"criticalTimeout": true}`))
+	assert.Error(t, err)
+
+	out, err := json.Marshal(Duration(2 * time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, `"2s"`, string(out))
+}
+
+func TestConfigJSONKeysAreCamelCase(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Suppress = []Suppression{{Fingerprint: "3f2a", Types: []ErrorType{errBotParse}, Message: "^bot"}}
+	cfg.Chaos = &Chaos{Probability: 0.1}
+	cfg.RendererConfig = &RendererConfig{Name: "json"}
+
+	out, err := json.Marshal(cfg)
+	require.NoError(t, err)
+
+	var keys map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(out, &keys))
+	for key := range keys {
+		assert.Regexp(t, `^[a-z][a-zA-Z]*$`, key)
+	}
+	var rules []map[string]any
+	require.NoError(t, json.Unmarshal(keys["suppress"], &rules))
+	assert.Equal(t, map[string]any{"fingerprint": "3f2a", "types": []any{float64(errBotParse)}, "message": "^bot"}, rules[0])
+
+	parsed, err := ParseConfig(out)
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Suppress, parsed.Suppress)
+	assert.Equal(t, cfg.MaxFrames, parsed.MaxFrames)
+}
@@ -0,0 +1,95 @@
+package xerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Renderer writes the debug error page for a handled error
+type Renderer interface {
+	ContentType() string
+	Render(w io.Writer, data *ErrorData) error
+}
+
+// RendererFactory builds a Renderer from the options given in the configuration
+type RendererFactory func(options map[string]any) (Renderer, error)
+
+// RendererConfig selects a registered renderer by name
+type RendererConfig struct {
+	Name    string         `json:"name"`
+	Options map[string]any `json:"options"`
+}
+
+var (
+	rendererFactoriesMu sync.RWMutex
+	rendererFactories   = map[string]RendererFactory{}
+)
+
+// Built-in renderers: "html" renders the error page template (option "template"
+// is the path of a custom one) and "json" writes the ErrorData as indented JSON.
+func init() {
+	RegisterRendererFactory("html", func(options map[string]any) (Renderer, error) {
+		path, _ := options["template"].(string)
+		return newTemplateRenderer(path)
+	})
+	RegisterRendererFactory("json", func(map[string]any) (Renderer, error) {
+		return jsonRenderer{}, nil
+	})
+}
+
+// RegisterRendererFactory makes a renderer available by name to RendererConfig.
+// It is meant to be called from the init function of the package providing the renderer
+// and panics if the name is registered twice or factory is nil.
+func RegisterRendererFactory(name string, factory RendererFactory) {
+	rendererFactoriesMu.Lock()
+	defer rendererFactoriesMu.Unlock()
+
+	if factory == nil {
+		panic("xerr: RegisterRendererFactory factory is nil")
+	}
+	if _, dup := rendererFactories[name]; dup {
+		panic("xerr: RegisterRendererFactory called twice for " + name)
+	}
+	rendererFactories[name] = factory
+}
+
+// RendererFactories returns the sorted names of the registered renderer factories
+func RendererFactories() []string {
+	rendererFactoriesMu.RLock()
+	defer rendererFactoriesMu.RUnlock()
+	return slices.Sorted(maps.Keys(rendererFactories))
+}
+
+// NewRenderer builds the renderer registered under name
+func NewRenderer(name string, options map[string]any) (Renderer, error) {
+	rendererFactoriesMu.RLock()
+	factory, ok := rendererFactories[name]
+	rendererFactoriesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown renderer %q (forgotten import?)", name)
+	}
+
+	renderer, err := factory(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create renderer %q: %w", name, err)
+	}
+	return renderer, nil
+}
+
+// jsonRenderer writes the error data as JSON, e.g. for API-only services
+type jsonRenderer struct{}
+
+func (jsonRenderer) ContentType() string {
+	return "application/json"
+}
+
+func (jsonRenderer) Render(w io.Writer, data *ErrorData) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
@@ -0,0 +1,74 @@
+package xerr
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// textRenderer writes the error message as plain text
+type textRenderer struct{}
+
+func (textRenderer) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (textRenderer) Render(w io.Writer, data *ErrorData) error {
+	_, err := io.WriteString(w, "failed: "+data.Error)
+	return err
+}
+
+func TestRegisterRendererFactoryPanics(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterRendererFactory("html", func(map[string]any) (Renderer, error) { return nil, nil })
+	})
+	assert.Panics(t, func() { RegisterRendererFactory("test-nil", nil) })
+	assert.Subset(t, RendererFactories(), []string{"html", "json"})
+}
+
+func TestNewRenderer(t *testing.T) {
+	r, err := NewRenderer("html", nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &templateRenderer{}, r)
+
+	_, err = NewRenderer("html", map[string]any{"template": "missing.html"})
+	assert.ErrorContains(t, err, `failed to create renderer "html"`)
+
+	_, err = NewRenderer("pdf", nil)
+	assert.ErrorContains(t, err, `unknown renderer "pdf"`)
+}
+
+func TestHandleErrorUsesConfiguredRenderer(t *testing.T) {
+	eh := NewErrorHandler(&Config{MaxFrames: 10, Renderer: textRenderer{}})
+	w := httptest.NewRecorder()
+
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/", nil), "boom")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "failed: boom", w.Body.String())
+}
+
+func TestHandleErrorUsesRegisteredRenderer(t *testing.T) {
+	config, err := ParseConfig([]byte(`{"renderer": {"name": "json"}}`))
+	require.NoError(t, err)
+	eh := NewErrorHandler(config)
+	w := httptest.NewRecorder()
+
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/", nil), "boom")
+
+	var data ErrorData
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &data))
+	assert.Equal(t, "boom", data.Error)
+	assert.NotEmpty(t, data.Frames)
+}
+
+func TestNewErrorHandlerPanicsOnUnknownRenderer(t *testing.T) {
+	assert.Panics(t, func() {
+		NewErrorHandler(&Config{RendererConfig: &RendererConfig{Name: "pdf"}})
+	})
+}
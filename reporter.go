@@ -0,0 +1,266 @@
+package xerr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultCriticalTimeout bounds critical deliveries when Config.CriticalTimeout is not set
+const defaultCriticalTimeout = 2 * time.Second
+
+// Reporter receives handled errors, e.g. to forward them to an error tracker.
+// Reports are delivered asynchronously; use (*ErrorHandler).Flush to wait for them.
+type Reporter interface {
+	Report(ctx context.Context, data *ErrorData) error
+}
+
+// Field is a set of heavy ErrorData fields that reporters can opt out of
+type Field uint
+
+const (
+	FieldSnippets Field = 1 << iota // Frame.Snippet of every frame
+	FieldRawStack                   // ErrorData.RawStack
+	FieldRequest                    // ErrorData.RequestInfo
+	FieldGroups                     // ErrorData.Groups
+
+	// AllFields includes every heavy field
+	AllFields = FieldSnippets | FieldRawStack | FieldRequest | FieldGroups
+)
+
+// FieldProjector is implemented by reporters that only need some of the heavy fields.
+// Reporters that don't implement it receive all fields.
+type FieldProjector interface {
+	Fields() Field
+}
+
+// fieldsOf returns the heavy fields needed by at least one of the reporters
+func fieldsOf(reporters []Reporter, critical Reporter) Field {
+	if critical != nil {
+		reporters = append(slices.Clip(reporters), critical)
+	}
+	var fields Field
+	for _, reporter := range reporters {
+		fields |= reporterFields(reporter)
+	}
+	return fields
+}
+
+// reporterFields returns the heavy fields reporter asks for
+func reporterFields(reporter Reporter) Field {
+	if p, ok := reporter.(FieldProjector); ok {
+		return p.Fields()
+	}
+	return AllFields
+}
+
+// project returns a copy of data without the live request and the heavy fields
+// not included in fields. Frames are only copied when snippets must be dropped.
+func project(data *ErrorData, fields Field) *ErrorData {
+	p := *data
+	p.Request = nil
+	if fields&FieldSnippets == 0 && hasSnippets(data.Frames) {
+		p.Frames = make([]Frame, len(data.Frames))
+		for i, f := range data.Frames {
+			f.Snippet = ""
+			p.Frames[i] = f
+		}
+	}
+	if fields&FieldRawStack == 0 {
+		p.RawStack = ""
+	}
+	if fields&FieldRequest == 0 {
+		p.RequestInfo = nil
+	}
+	if fields&FieldGroups == 0 {
+		p.Groups = nil
+	}
+	return &p
+}
+
+// hasSnippets reports whether any frame carries a snippet
+func hasSnippets(frames []Frame) bool {
+	return slices.ContainsFunc(frames, func(f Frame) bool { return f.Snippet != "" })
+}
+
+// dispatch delivers data to every configured reporter in the background.
+// Critical errors are delivered to the critical reporter before it returns,
+// bounded by Config.CriticalTimeout.
+func (eh *ErrorHandler) dispatch(err interface{}, data *ErrorData) {
+	for _, reporter := range eh.reporters {
+		eh.deliver(context.Background(), reporter, data)
+	}
+
+	critical := eh.config.CriticalReporter
+	if critical == nil {
+		return
+	}
+	if !isCritical(err) {
+		eh.deliver(context.Background(), critical, data)
+		return
+	}
+	eh.deliverCritical(data)
+}
+
+// dispatchSuppressed delivers a suppressed error to the critical reporter when it is
+// critical, as suppression rules must not drop errors that may not be lost.
+// data is the summary the rules matched on, it is only completed for that delivery.
+func (eh *ErrorHandler) dispatchSuppressed(r *http.Request, err interface{}, data *ErrorData) {
+	if eh.config.CriticalReporter != nil && isCritical(err) {
+		eh.completeData(data, r, err, eh.reportFields)
+		eh.deliverCritical(data)
+	}
+}
+
+// deliverCritical delivers data to the critical reporter and waits for it,
+// bounded by Config.CriticalTimeout
+func (eh *ErrorHandler) deliverCritical(data *ErrorData) {
+	ctx, cancel := context.WithTimeout(context.Background(), eh.criticalTimeout())
+	defer cancel()
+	select {
+	case <-eh.deliver(ctx, eh.config.CriticalReporter, data):
+	case <-ctx.Done():
+		eh.reportError(fmt.Errorf("critical reporter: %w", ctx.Err()))
+	}
+}
+
+// deliver sends the projection of data wanted by reporter in the background.
+// The returned channel is closed once the reporter returns.
+func (eh *ErrorHandler) deliver(ctx context.Context, reporter Reporter, data *ErrorData) <-chan struct{} {
+	done := make(chan struct{})
+	eh.reports.add()
+	go func(data *ErrorData) {
+		defer eh.reports.done()
+		defer close(done)
+		defer func() {
+			if rec := recover(); rec != nil {
+				eh.reportError(fmt.Errorf("reporter panic: %v", rec))
+			}
+		}()
+		if err := reporter.Report(ctx, data); err != nil {
+			eh.reportError(err)
+		}
+	}(eh.sign(project(data, reporterFields(reporter))))
+	return done
+}
+
+// sign sets the signature of a reporter projection when a signing key is configured.
+// Each projection is signed on its own so every reporter receives data it can verify.
+func (eh *ErrorHandler) sign(data *ErrorData) *ErrorData {
+	if len(eh.config.SigningKey) > 0 {
+		data.Signature = data.Sign(eh.config.SigningKey)
+	}
+	return data
+}
+
+// reportError passes a failed delivery to Config.OnReportError
+func (eh *ErrorHandler) reportError(err error) {
+	if eh.config.OnReportError != nil {
+		eh.config.OnReportError(err)
+	}
+}
+
+// criticalTimeout returns how long a critical delivery may block
+func (eh *ErrorHandler) criticalTimeout() time.Duration {
+	if eh.config.CriticalTimeout <= 0 {
+		return defaultCriticalTimeout
+	}
+	return time.Duration(eh.config.CriticalTimeout)
+}
+
+// isCritical reports whether err is an XErr marked as critical, or wraps one
+func isCritical(err interface{}) bool {
+	e, ok := err.(error)
+	if !ok {
+		return false
+	}
+	xe, ok := As(e)
+	return ok && xe.Critical
+}
+
+// Report sends err to the configured reporters without writing an HTTP response
+func (eh *ErrorHandler) Report(err interface{}) {
+	data := eh.errorSummary(nil, err)
+	if eh.suppressed(err, data) {
+		eh.dispatchSuppressed(nil, err, data)
+		return
+	}
+	eh.completeData(data, nil, err, eh.reportFields)
+	eh.dispatch(err, data)
+}
+
+// Flush waits until all in-flight reports are delivered or ctx is done.
+// It is safe to call while errors are still being handled.
+func (eh *ErrorHandler) Flush(ctx context.Context) error {
+	select {
+	case <-eh.reports.idle():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// inflight counts the reports being delivered. Unlike a sync.WaitGroup,
+// reports may be added while another goroutine waits for them.
+type inflight struct {
+	mu     sync.Mutex
+	count  int
+	zeroed chan struct{} // closed once count drops back to zero
+}
+
+func (f *inflight) add() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.count == 0 {
+		f.zeroed = make(chan struct{})
+	}
+	f.count++
+}
+
+func (f *inflight) done() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.count--
+	if f.count == 0 {
+		close(f.zeroed)
+	}
+}
+
+// idle returns a channel closed once no report is in flight
+func (f *inflight) idle() <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.count == 0 {
+		return closedChan
+	}
+	return f.zeroed
+}
+
+// closedChan is returned by idle when nothing is in flight
+var closedChan = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// RequestInfo is a snapshot of the request an error occurred in.
+// Unlike ErrorData.Request it stays valid after the handler returns.
+type RequestInfo struct {
+	Method     string
+	URL        string
+	Header     http.Header
+	RemoteAddr string
+}
+
+// newRequestInfo snapshots r
+func newRequestInfo(r *http.Request) *RequestInfo {
+	return &RequestInfo{
+		Method:     r.Method,
+		URL:        r.URL.String(),
+		Header:     r.Header.Clone(),
+		RemoteAddr: r.RemoteAddr,
+	}
+}
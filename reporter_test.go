@@ -0,0 +1,257 @@
+package xerr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingReporter stores every report it receives
+type recordingReporter struct {
+	mu      sync.Mutex
+	reports []*ErrorData
+	err     error
+	delay   time.Duration
+}
+
+func (rr *recordingReporter) Report(ctx context.Context, data *ErrorData) error {
+	time.Sleep(rr.delay)
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.reports = append(rr.reports, data)
+	return rr.err
+}
+
+func (rr *recordingReporter) received() []*ErrorData {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	return append([]*ErrorData(nil), rr.reports...)
+}
+
+// panickingReporter panics on every report
+type panickingReporter struct{}
+
+func (panickingReporter) Report(context.Context, *ErrorData) error {
+	panic("broken reporter")
+}
+
+// projectingReporter only asks for the raw stack
+type projectingReporter struct {
+	recordingReporter
+}
+
+func (pr *projectingReporter) Fields() Field {
+	return FieldRawStack
+}
+
+func TestHandleErrorDispatchesToReporters(t *testing.T) {
+	full := &recordingReporter{}
+	slim := &projectingReporter{}
+	eh := NewErrorHandler(&Config{ShowSourceCode: true, MaxFrames: 10, Reporters: []Reporter{full, slim}})
+
+	eh.HandleError(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), New("boom", ErrUnknown, nil))
+	assert.NoError(t, eh.Flush(context.Background()))
+
+	assert.Len(t, full.received(), 1)
+	assert.Nil(t, full.received()[0].Request, "Reporters must not receive the live request")
+	assert.Equal(t, "/", full.received()[0].RequestInfo.URL)
+	assert.NotEmpty(t, full.received()[0].Frames[0].Snippet)
+
+	assert.Len(t, slim.received(), 1)
+	got := slim.received()[0]
+	assert.Nil(t, got.Request)
+	assert.Nil(t, got.RequestInfo)
+	assert.Nil(t, got.Groups)
+	assert.NotEmpty(t, got.RawStack)
+	assert.NotEmpty(t, got.Frames)
+	for _, f := range got.Frames {
+		assert.Empty(t, f.Snippet)
+	}
+	assert.NotEmpty(t, full.received()[0].Frames[0].Snippet, "Projection must not modify the shared data")
+}
+
+func TestReportSkipsSuppressedErrors(t *testing.T) {
+	rr := &recordingReporter{}
+	eh := NewErrorHandler(&Config{Reporters: []Reporter{rr}, Suppress: []Suppression{{Message: "noisy"}}})
+
+	eh.Report(errors.New("noisy error"))
+	eh.Report(errors.New("real error"))
+	assert.NoError(t, eh.Flush(context.Background()))
+
+	assert.Len(t, rr.received(), 1)
+	assert.Equal(t, "real error", rr.received()[0].Error)
+	assert.Equal(t, uint64(1), eh.SuppressedCount())
+}
+
+func TestReportErrorsAreSurfaced(t *testing.T) {
+	var mu sync.Mutex
+	var failures []error
+	rr := &recordingReporter{err: errors.New("tracker down")}
+	eh := NewErrorHandler(&Config{
+		Reporters: []Reporter{rr},
+		OnReportError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failures = append(failures, err)
+		},
+	})
+
+	eh.Report("boom")
+	assert.NoError(t, eh.Flush(context.Background()))
+	assert.Equal(t, []error{rr.err}, failures)
+}
+
+func TestFlushHonorsContext(t *testing.T) {
+	rr := &recordingReporter{delay: 200 * time.Millisecond}
+	eh := NewErrorHandler(&Config{Reporters: []Reporter{rr}})
+	eh.Report("slow")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, eh.Flush(ctx), context.DeadlineExceeded)
+	assert.NoError(t, eh.Flush(context.Background()))
+}
+
+func TestProjectDropsLiveRequest(t *testing.T) {
+	frames := []Frame{{Function: "main.main", Snippet: "code"}}
+	data := &ErrorData{Error: "x", Frames: frames, Request: httptest.NewRequest(http.MethodGet, "/", nil), RequestInfo: &RequestInfo{Method: http.MethodGet}}
+
+	p := project(data, AllFields)
+	assert.Nil(t, p.Request)
+	assert.Equal(t, data.RequestInfo, p.RequestInfo)
+	assert.Equal(t, "code", p.Frames[0].Snippet)
+	assert.Same(t, &frames[0], &p.Frames[0], "Frames are shared when snippets are kept")
+	assert.NotNil(t, data.Request, "Projection must not modify the shared data")
+}
+
+func TestReporterPanicIsReported(t *testing.T) {
+	var errs []error
+	var mu sync.Mutex
+	eh := NewErrorHandler(&Config{
+		Reporters: []Reporter{panickingReporter{}},
+		OnReportError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		},
+	})
+
+	eh.Report(errors.New("boom"))
+	assert.NoError(t, eh.Flush(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "reporter panic: broken reporter")
+}
+
+func TestReportOnlyComputesRequestedFields(t *testing.T) {
+	slim := &projectingReporter{}
+	eh := NewErrorHandler(&Config{ShowSourceCode: true, MaxFrames: 10, Reporters: []Reporter{slim}})
+
+	data := eh.errorData(nil, New("boom", ErrUnknown, nil), eh.reportFields)
+	assert.NotEmpty(t, data.RawStack)
+	assert.Nil(t, data.Groups)
+	for _, f := range data.Frames {
+		assert.Empty(t, f.Snippet)
+	}
+}
+
+func TestCriticalErrorsAreDeliveredBeforeResponse(t *testing.T) {
+	audit := &recordingReporter{delay: 50 * time.Millisecond}
+	other := &recordingReporter{delay: 300 * time.Millisecond}
+	eh := NewErrorHandler(&Config{Reporters: []Reporter{other}, CriticalReporter: audit})
+
+	eh.HandleError(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/transfer", nil),
+		New("ledger mismatch", ErrUnknown, nil).WithCritical())
+	assert.Len(t, audit.received(), 1, "Critical errors must be delivered synchronously")
+	assert.Empty(t, other.received(), "Other reporters stay asynchronous")
+
+	eh.Report(errors.New("not critical"))
+	assert.Len(t, audit.received(), 1, "Non critical errors are delivered asynchronously")
+
+	assert.NoError(t, eh.Flush(context.Background()))
+	assert.Len(t, audit.received(), 2)
+	assert.Len(t, other.received(), 2)
+}
+
+func TestCriticalDeliveryIsBounded(t *testing.T) {
+	var mu sync.Mutex
+	var failures []error
+	audit := &recordingReporter{delay: time.Second}
+	eh := NewErrorHandler(&Config{
+		CriticalReporter: audit,
+		CriticalTimeout:  Duration(20 * time.Millisecond),
+		OnReportError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failures = append(failures, err)
+		},
+	})
+
+	start := time.Now()
+	eh.Report(New("ledger mismatch", ErrUnknown, nil).WithCritical())
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, failures, 1)
+	assert.ErrorIs(t, failures[0], context.DeadlineExceeded)
+}
+
+func TestSuppressedCriticalErrorsReachCriticalReporter(t *testing.T) {
+	audit := &recordingReporter{}
+	other := &recordingReporter{}
+	eh := NewErrorHandler(&Config{
+		Reporters:        []Reporter{other},
+		CriticalReporter: audit,
+		Suppress:         []Suppression{{Message: "ledger"}},
+	})
+
+	w := httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodPost, "/transfer", nil), New("ledger mismatch", ErrUnknown, nil).WithCritical())
+	assert.Equal(t, "Internal Server Error", w.Body.String(), "The response is still the suppressed one")
+	assert.Len(t, audit.received(), 1)
+
+	eh.Report(New("ledger mismatch", ErrUnknown, nil))
+	assert.NoError(t, eh.Flush(context.Background()))
+	assert.Len(t, audit.received(), 1, "Suppressed non critical errors are dropped")
+	assert.Empty(t, other.received())
+}
+
+func TestFlushWhileReporting(t *testing.T) {
+	rr := &recordingReporter{delay: time.Millisecond}
+	eh := NewErrorHandler(&Config{Reporters: []Reporter{rr}})
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				eh.Report("boom")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				_ = eh.Flush(context.Background())
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.NoError(t, eh.Flush(context.Background()))
+	assert.Len(t, rr.received(), 200)
+}
+
+func TestFlushWithoutReports(t *testing.T) {
+	eh := NewErrorHandler(nil)
+	assert.NoError(t, eh.Flush(context.Background()))
+}
@@ -0,0 +1,269 @@
+package xerr
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultMaxSamples is used when sampling is enabled without a MaxSamples limit
+const defaultMaxSamples = 100
+
+// maxSampledRoutes bounds the number of routes kept in the sample store
+const maxSampledRoutes = 1000
+
+// maxBreadcrumbs bounds the trail kept per request, older breadcrumbs are dropped first
+const maxBreadcrumbs = 32
+
+// Breadcrumb marks a step reached by a request, see AddBreadcrumb
+type Breadcrumb struct {
+	Message string
+	At      time.Duration // Time since the start of the request
+}
+
+// Baseline summarizes sampled timings of successful requests to the same route
+type Baseline struct {
+	Samples     int
+	Average     time.Duration
+	Max         time.Duration
+	Breadcrumbs map[string]time.Duration // Average time at which each breadcrumb was reached
+}
+
+// sample is the timing and breadcrumb trail of one successful request
+type sample struct {
+	elapsed time.Duration
+	trail   []Breadcrumb
+}
+
+// sampleStore keeps a bounded window of successful request samples per route
+type sampleStore struct {
+	mu     sync.Mutex
+	max    int
+	routes map[string][]sample
+}
+
+func newSampleStore(max int) *sampleStore {
+	if max <= 0 {
+		max = defaultMaxSamples
+	}
+	return &sampleStore{max: max, routes: make(map[string][]sample)}
+}
+
+// add records a sample for route, dropping the oldest one when the window is full
+func (s *sampleStore) add(route string, smp sample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	samples, ok := s.routes[route]
+	if !ok && len(s.routes) >= maxSampledRoutes {
+		return
+	}
+	if len(samples) >= s.max {
+		samples = samples[1:]
+	}
+	s.routes[route] = append(samples, smp)
+}
+
+// baseline returns the summary for route, or nil when nothing was sampled
+func (s *sampleStore) baseline(route string) *Baseline {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	samples := s.routes[route]
+	if len(samples) == 0 {
+		return nil
+	}
+
+	b := &Baseline{Samples: len(samples)}
+	var total time.Duration
+	crumbTotals := make(map[string]time.Duration)
+	crumbCounts := make(map[string]int)
+	for _, smp := range samples {
+		total += smp.elapsed
+		if smp.elapsed > b.Max {
+			b.Max = smp.elapsed
+		}
+
+		seen := make(map[string]bool, len(smp.trail))
+		for _, c := range smp.trail {
+			if seen[c.Message] {
+				continue // only the first time a step is reached is compared
+			}
+			seen[c.Message] = true
+			crumbTotals[c.Message] += c.At
+			crumbCounts[c.Message]++
+		}
+	}
+	b.Average = total / time.Duration(len(samples))
+
+	if len(crumbTotals) > 0 {
+		b.Breadcrumbs = make(map[string]time.Duration, len(crumbTotals))
+		for message, t := range crumbTotals {
+			b.Breadcrumbs[message] = t / time.Duration(crumbCounts[message])
+		}
+	}
+	return b
+}
+
+// requestState is the per-request state the middleware shares with HandleError
+type requestState struct {
+	start   time.Time
+	errID   string    // ID of the error handled during the request, if any
+	errType ErrorType // Type of that error
+
+	mu    sync.Mutex
+	trail []Breadcrumb
+}
+
+// AddBreadcrumb records that the request handled by the middleware reached a step,
+// e.g. before and after a database query. The trail is shown on the error page and,
+// when sampling is enabled, sampled trails tell when each step is normally reached.
+// It does nothing outside the middleware.
+func AddBreadcrumb(ctx context.Context, message string) {
+	state, _ := ctx.Value(requestStateKey{}).(*requestState)
+	if state == nil {
+		return
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if len(state.trail) >= maxBreadcrumbs {
+		state.trail = state.trail[1:]
+	}
+	state.trail = append(state.trail, Breadcrumb{Message: message, At: time.Since(state.start)})
+}
+
+// breadcrumbs returns a copy of the trail recorded so far
+func (state *requestState) breadcrumbs() []Breadcrumb {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return slices.Clone(state.trail)
+}
+
+type requestStateKey struct{}
+
+// stateFromRequest returns the middleware state for r, or nil outside the middleware
+func stateFromRequest(r *http.Request) *requestState {
+	if r == nil {
+		return nil
+	}
+	state, _ := r.Context().Value(requestStateKey{}).(*requestState)
+	return state
+}
+
+// withRequestState attaches a fresh requestState to r
+func withRequestState(r *http.Request) (*http.Request, *requestState) {
+	state := &requestState{start: time.Now()}
+	return r.WithContext(context.WithValue(r.Context(), requestStateKey{}, state)), state
+}
+
+// statusRecorder captures the status code written by the wrapped handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	if sr.status == 0 {
+		sr.status = code
+	}
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(b)
+}
+
+// ReadFrom keeps the io.ReaderFrom fast path of the wrapped writer.
+// It falls back to io.Copy, so it is safe to offer on every recorder.
+func (sr *statusRecorder) ReadFrom(src io.Reader) (int64, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	if rf, ok := sr.ResponseWriter.(io.ReaderFrom); ok {
+		return rf.ReadFrom(src)
+	}
+	return io.Copy(struct{ io.Writer }{sr.ResponseWriter}, src)
+}
+
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+// Status returns the recorded status code, 0 for a nil recorder or when nothing was written
+func (sr *statusRecorder) Status() int {
+	if sr == nil {
+		return 0
+	}
+	return sr.status
+}
+
+func (sr *statusRecorder) flush() {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	sr.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (sr *statusRecorder) hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return sr.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// Recorders that keep the http.Flusher and http.Hijacker support of the wrapped writer
+type (
+	flushRecorder       struct{ *statusRecorder }
+	hijackRecorder      struct{ *statusRecorder }
+	flushHijackRecorder struct{ *statusRecorder }
+)
+
+func (r flushRecorder) Flush()       { r.flush() }
+func (r flushHijackRecorder) Flush() { r.flush() }
+
+func (r hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error)      { return r.hijack() }
+func (r flushHijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) { return r.hijack() }
+
+// wrapRecorder returns sr as a ResponseWriter offering the same optional
+// interfaces as the writer it wraps
+func wrapRecorder(sr *statusRecorder) http.ResponseWriter {
+	_, flusher := sr.ResponseWriter.(http.Flusher)
+	_, hijacker := sr.ResponseWriter.(http.Hijacker)
+	switch {
+	case flusher && hijacker:
+		return flushHijackRecorder{sr}
+	case flusher:
+		return flushRecorder{sr}
+	case hijacker:
+		return hijackRecorder{sr}
+	default:
+		return sr
+	}
+}
+
+// routeKey identifies the route of r for sampling
+func routeKey(r *http.Request) string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	return r.Method + " " + r.URL.Path
+}
+
+// sampleRequest records the timing and breadcrumb trail of a successful request
+// if it is picked by the sample rate
+func (eh *ErrorHandler) sampleRequest(r *http.Request, status int, state *requestState) {
+	if eh.samples == nil || status >= http.StatusBadRequest {
+		return
+	}
+	if rand.Float64() >= eh.config.SampleRate {
+		return
+	}
+	eh.samples.add(routeKey(r), sample{elapsed: time.Since(state.start), trail: state.breadcrumbs()})
+}
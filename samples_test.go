@@ -0,0 +1,143 @@
+package xerr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleStoreKeepsBoundedWindow(t *testing.T) {
+	s := newSampleStore(3)
+	assert.Nil(t, s.baseline("GET /"))
+
+	for _, d := range []time.Duration{10, 20, 30, 40} {
+		s.add("GET /", sample{elapsed: d * time.Millisecond})
+	}
+
+	b := s.baseline("GET /")
+	assert.Equal(t, 3, b.Samples)
+	assert.Equal(t, 30*time.Millisecond, b.Average)
+	assert.Equal(t, 40*time.Millisecond, b.Max)
+	assert.Nil(t, b.Breadcrumbs)
+}
+
+func TestBaselineAveragesBreadcrumbs(t *testing.T) {
+	s := newSampleStore(10)
+	s.add("GET /", sample{elapsed: 10 * time.Millisecond, trail: []Breadcrumb{{"query", 4 * time.Millisecond}, {"query", 8 * time.Millisecond}}})
+	s.add("GET /", sample{elapsed: 10 * time.Millisecond, trail: []Breadcrumb{{"query", 6 * time.Millisecond}, {"render", 9 * time.Millisecond}}})
+
+	b := s.baseline("GET /")
+	assert.Equal(t, map[string]time.Duration{"query": 5 * time.Millisecond, "render": 9 * time.Millisecond}, b.Breadcrumbs)
+}
+
+func TestMiddlewareRecordsBreadcrumbs(t *testing.T) {
+	eh := NewErrorHandler(&Config{SampleRate: 1, MaxFrames: 10})
+
+	fail := false
+	h := eh.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		AddBreadcrumb(r.Context(), "query orders")
+		if fail {
+			panic("query failed")
+		}
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+	assert.Contains(t, eh.samples.baseline("GET /orders").Breadcrumbs, "query orders")
+
+	fail = true
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	assert.Contains(t, w.Body.String(), "Breadcrumbs:")
+	assert.Contains(t, w.Body.String(), "query orders (normally")
+}
+
+func TestAddBreadcrumbKeepsBoundedTrail(t *testing.T) {
+	r, state := withRequestState(httptest.NewRequest(http.MethodGet, "/", nil))
+	for i := range maxBreadcrumbs + 5 {
+		AddBreadcrumb(r.Context(), fmt.Sprint(i))
+	}
+
+	trail := state.breadcrumbs()
+	assert.Len(t, trail, maxBreadcrumbs)
+	assert.Equal(t, "5", trail[0].Message, "The oldest breadcrumbs are dropped first")
+
+	AddBreadcrumb(context.Background(), "outside the middleware")
+}
+
+func TestMiddlewareSamplesSuccessfulRequests(t *testing.T) {
+	eh := NewErrorHandler(&Config{SampleRate: 1, MaxFrames: 10})
+
+	fail := false
+	h := eh.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			panic("slow panic")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for range 5 {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+	}
+	assert.Equal(t, 5, eh.samples.baseline("GET /orders").Samples)
+
+	fail = true
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Baseline:")
+	assert.Contains(t, w.Body.String(), "5 samples")
+	assert.Equal(t, 5, eh.samples.baseline("GET /orders").Samples, "Failed requests should not be sampled")
+}
+
+func TestMiddlewareSamplingDisabledByDefault(t *testing.T) {
+	eh := NewErrorHandler(nil)
+	assert.Nil(t, eh.samples)
+
+	h := eh.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestMiddlewareSkipsClientErrorsWhenSampling(t *testing.T) {
+	eh := NewErrorHandler(&Config{SampleRate: 1, MaxFrames: 10})
+	h := eh.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	assert.Nil(t, eh.samples.baseline("GET /missing"))
+}
+
+func TestMiddlewareKeepsOptionalWriterInterfaces(t *testing.T) {
+	for name, config := range map[string]*Config{
+		"default":  nil,
+		"sampling": {SampleRate: 1, MaxFrames: 10},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var flusher, readerFrom bool
+			h := NewErrorHandler(config).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, flusher = w.(http.Flusher)
+				_, readerFrom = w.(io.ReaderFrom)
+				_, hijacker := w.(http.Hijacker)
+				assert.False(t, hijacker, "Hijacker should not be offered when the writer lacks it")
+			}))
+
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.True(t, flusher)
+			assert.Equal(t, name == "sampling", readerFrom)
+		})
+	}
+}
+
+func TestStatusRecorderFlushRecordsStatus(t *testing.T) {
+	sr := &statusRecorder{ResponseWriter: httptest.NewRecorder()}
+	wrapRecorder(sr).(http.Flusher).Flush()
+
+	assert.Equal(t, http.StatusOK, sr.Status())
+	assert.Zero(t, (*statusRecorder)(nil).Status())
+}
@@ -0,0 +1,74 @@
+package xerr
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+var (
+	statusCodesMu sync.RWMutex
+	statusCodes   = map[ErrorType]int{
+		ErrRateLimited: http.StatusTooManyRequests,
+	}
+)
+
+// SetStatusCode maps an ErrorType to the HTTP status returned by StatusCode
+func SetStatusCode(t ErrorType, status int) {
+	statusCodesMu.Lock()
+	defer statusCodesMu.Unlock()
+	statusCodes[t] = status
+}
+
+// StatusCode returns the HTTP status for err.
+// It returns the mapped status for an XErr in the chain, or 500.
+func StatusCode(err error) int {
+	xe, ok := As(err)
+	if !ok {
+		return http.StatusInternalServerError
+	}
+
+	statusCodesMu.RLock()
+	defer statusCodesMu.RUnlock()
+	if status, ok := statusCodes[xe.Type]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// PublicError is the JSON body sent to clients for an error.
+// It only contains the public message and details, never the internal message.
+type PublicError struct {
+	Status  int            `json:"status"`
+	Type    ErrorType      `json:"type"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// NewPublicError builds the client facing representation of err.
+// Values that aren't errors (e.g. recovered panics) become a generic 500.
+func NewPublicError(err interface{}) *PublicError {
+	e, _ := err.(error)
+	status := StatusCode(e)
+	pe := &PublicError{Status: status, Type: ErrUnknown, Message: http.StatusText(status)}
+
+	if xe, ok := As(e); ok {
+		pe.Type = xe.Type
+		pe.Details = xe.Details
+		if xe.PublicMessage != "" {
+			pe.Message = xe.PublicMessage
+		}
+	}
+	return pe
+}
+
+// PublicResponse returns the HTTP status and JSON body to send to clients for err
+func PublicResponse(err interface{}) (int, []byte) {
+	pe := NewPublicError(err)
+	body, marshalErr := json.Marshal(pe)
+	if marshalErr != nil {
+		pe.Details = nil
+		body, _ = json.Marshal(pe)
+	}
+	return pe.Status, body
+}
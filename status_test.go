@@ -0,0 +1,49 @@
+package xerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const errNotFound ErrorType = iota + 5000
+
+func TestStatusCode(t *testing.T) {
+	SetStatusCode(errNotFound, http.StatusNotFound)
+
+	assert.Equal(t, http.StatusInternalServerError, StatusCode(nil))
+	assert.Equal(t, http.StatusInternalServerError, StatusCode(errors.New("plain")))
+	assert.Equal(t, http.StatusInternalServerError, StatusCode(New("unknown", ErrUnknown, nil)))
+	assert.Equal(t, http.StatusNotFound, StatusCode(fmt.Errorf("wrapped: %w", New("missing", errNotFound, nil))))
+}
+
+func TestPublicResponse(t *testing.T) {
+	SetStatusCode(errNotFound, http.StatusNotFound)
+
+	status, body := PublicResponse(New("user 42 missing in db", errNotFound, nil).
+		WithPublicMessage("User not found").
+		WithDetails(map[string]any{"id": 42}))
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.JSONEq(t, `{"status": 404, "type": 5000, "message": "User not found", "details": {"id": 42}}`, string(body))
+
+	status, body = PublicResponse(New("internal detail", errNotFound, nil))
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.JSONEq(t, `{"status": 404, "type": 5000, "message": "Not Found"}`, string(body))
+
+	status, body = PublicResponse("panic value")
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.JSONEq(t, `{"status": 500, "type": 0, "message": "Internal Server Error"}`, string(body))
+
+	_, body = PublicResponse(New("bad details", errNotFound, nil).WithDetails(map[string]any{"fn": func() {}}))
+	assert.JSONEq(t, `{"status": 404, "type": 5000, "message": "Not Found"}`, string(body), "Unencodable details are dropped")
+}
+
+func TestBuiltinTypesDoNotCollideWithApplicationTypes(t *testing.T) {
+	for _, typ := range []ErrorType{1, 2, 1000} {
+		assert.Equal(t, http.StatusInternalServerError, StatusCode(New("x", typ, nil)))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, StatusCode(New("x", ErrRateLimited, nil)))
+}
@@ -0,0 +1,124 @@
+package xerr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+)
+
+// Suppression silences a known noisy error.
+// Every non-empty field must match for the rule to apply.
+type Suppression struct {
+	Fingerprint string      `json:"fingerprint"` // Exact fingerprint as shown on the error page
+	Types       []ErrorType `json:"types"`       // Matches an XErr of any of these types in the error chain
+	Message     string      `json:"message"`     // Regular expression matched against the error message
+}
+
+// compiledSuppression is a Suppression with its message pattern compiled
+type compiledSuppression struct {
+	Suppression
+	message *regexp.Regexp
+}
+
+// compileSuppressions compiles the message patterns of the configured rules
+func compileSuppressions(rules []Suppression) ([]compiledSuppression, error) {
+	compiled := make([]compiledSuppression, 0, len(rules))
+	for _, rule := range rules {
+		c := compiledSuppression{Suppression: rule}
+		if rule.Message != "" {
+			re, err := regexp.Compile(rule.Message)
+			if err != nil {
+				return nil, fmt.Errorf("invalid suppression message pattern %q: %w", rule.Message, err)
+			}
+			c.message = re
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// matches reports whether the rule applies to the error described by data
+func (c *compiledSuppression) matches(err interface{}, data *ErrorData) bool {
+	if c.Fingerprint == "" && len(c.Types) == 0 && c.message == nil {
+		return false
+	}
+	if c.Fingerprint != "" && c.Fingerprint != data.Fingerprint {
+		return false
+	}
+	if len(c.Types) > 0 {
+		e, ok := err.(error)
+		if !ok {
+			return false
+		}
+		if xe, ok := As(e); !ok || !slices.Contains(c.Types, xe.Type) {
+			return false
+		}
+	}
+	if c.message != nil && !c.message.MatchString(data.Error) {
+		return false
+	}
+	return true
+}
+
+// suppressed reports whether any suppression rule applies and counts the match
+func (eh *ErrorHandler) suppressed(err interface{}, data *ErrorData) bool {
+	for i := range eh.suppressions {
+		if eh.suppressions[i].matches(err, data) {
+			eh.suppressedCount.Add(1)
+			return true
+		}
+	}
+	return false
+}
+
+// SuppressedCount returns how many errors were silenced by suppression rules
+func (eh *ErrorHandler) SuppressedCount() uint64 {
+	return eh.suppressedCount.Load()
+}
+
+// writeSuppressed writes a minimal response for a suppressed error, with the status
+// and rate limit headers of the full one, or the public JSON error with Config.PublicJSON
+func (eh *ErrorHandler) writeSuppressed(w http.ResponseWriter, err interface{}) {
+	if eh.config.PublicJSON {
+		eh.writePublic(w, err)
+		return
+	}
+
+	for key, value := range rateLimitHeaders(err) {
+		w.Header().Set(key, value)
+	}
+	e, _ := err.(error)
+	status := StatusCode(e)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(http.StatusText(status)))
+}
+
+// fingerprint identifies errors of the same kind raised from the same place.
+// It hashes the error kind (XErr type or Go type) and the first application frame,
+// so errors with varying messages still group together.
+func fingerprint(err interface{}, frames []Frame) string {
+	kind := fmt.Sprintf("%T", err)
+	if e, ok := err.(error); ok {
+		if xe, ok := As(e); ok {
+			kind = fmt.Sprintf("xerr:%d", xe.Type)
+		}
+	}
+
+	origin := ""
+	for _, f := range frames {
+		if f.App {
+			origin = fmt.Sprintf("%s:%d", f.Function, f.Line)
+			break
+		}
+	}
+	if origin == "" && len(frames) > 0 {
+		origin = fmt.Sprintf("%s:%d", frames[0].Function, frames[0].Line)
+	}
+
+	sum := sha256.Sum256([]byte(kind + "|" + origin))
+	return hex.EncodeToString(sum[:8])
+}
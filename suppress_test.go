@@ -0,0 +1,116 @@
+package xerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const errBotParse ErrorType = iota + 4000
+
+func TestSuppressionByType(t *testing.T) {
+	eh := NewErrorHandler(&Config{Suppress: []Suppression{{Types: []ErrorType{errBotParse}}}})
+
+	w := httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/", nil), fmt.Errorf("wrapped: %w", New("bad query", errBotParse, nil)))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "Internal Server Error", w.Body.String())
+	assert.Equal(t, uint64(1), eh.SuppressedCount())
+
+	w = httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/", nil), New("other", ErrUnknown, nil))
+	assert.Contains(t, w.Body.String(), "<html")
+	assert.Equal(t, uint64(1), eh.SuppressedCount())
+}
+
+func TestSuppressionByMessage(t *testing.T) {
+	eh := NewErrorHandler(&Config{Suppress: []Suppression{{Message: `^malformed (json|xml)`}}})
+
+	w := httptest.NewRecorder()
+	eh.HandleError(w, nil, errors.New("malformed json body"))
+	assert.Equal(t, "Internal Server Error", w.Body.String())
+
+	w = httptest.NewRecorder()
+	eh.HandleError(w, nil, errors.New("database down"))
+	assert.Contains(t, w.Body.String(), "database down")
+	assert.Equal(t, uint64(1), eh.SuppressedCount())
+}
+
+func TestSuppressionByFingerprint(t *testing.T) {
+	raise := func() *XErr { return New("noisy", ErrUnknown, nil) }
+	eh := NewErrorHandler(nil)
+	fp := fingerprint(raise(), eh.stackFrames(raise()))
+
+	eh = NewErrorHandler(&Config{Suppress: []Suppression{{Fingerprint: fp}}})
+	w := httptest.NewRecorder()
+	eh.HandleError(w, nil, raise())
+	assert.Equal(t, "Internal Server Error", w.Body.String())
+	assert.Equal(t, uint64(1), eh.SuppressedCount())
+}
+
+func TestSuppressionEmptyRuleNeverMatches(t *testing.T) {
+	eh := NewErrorHandler(&Config{Suppress: []Suppression{{}}})
+	w := httptest.NewRecorder()
+	eh.HandleError(w, nil, "boom")
+	assert.Contains(t, w.Body.String(), "boom")
+	assert.Equal(t, uint64(0), eh.SuppressedCount())
+}
+
+func TestSuppressionInvalidPatternPanics(t *testing.T) {
+	assert.Panics(t, func() {
+		NewErrorHandler(&Config{Suppress: []Suppression{{Message: "("}}})
+	})
+}
+
+func TestFingerprintIgnoresMessage(t *testing.T) {
+	frames := []Frame{{Function: "main.handler", File: "/app/main.go", Line: 10}}
+	a := fingerprint(New("user 1 not found", errBotParse, nil), frames)
+	b := fingerprint(New("user 2 not found", errBotParse, nil), frames)
+	c := fingerprint(New("user 2 not found", ErrUnknown, nil), frames)
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+	assert.Len(t, a, 16)
+}
+
+func TestSuppressedResponseKeepsStatusAndHeaders(t *testing.T) {
+	SetStatusCode(errBotParse, http.StatusBadRequest)
+	defer SetStatusCode(errBotParse, http.StatusInternalServerError)
+	eh := NewErrorHandler(&Config{Suppress: []Suppression{{Types: []ErrorType{errBotParse, ErrRateLimited}}}})
+
+	w := httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/", nil), New("bad query", errBotParse, nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Bad Request", w.Body.String())
+
+	w = httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/", nil), RateLimited(10, 0, time.Now().Add(time.Minute)))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "10", w.Header().Get("RateLimit-Limit"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestSuppressedResponseIsPublicJSON(t *testing.T) {
+	eh := NewErrorHandler(&Config{PublicJSON: true, Suppress: []Suppression{{Message: "noisy"}}})
+
+	w := httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/", nil), New("noisy", ErrUnknown, nil).WithPublicMessage("Try again"))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"status": 500, "type": 0, "message": "Try again"}`, w.Body.String())
+	assert.Equal(t, uint64(1), eh.SuppressedCount())
+}
+
+func TestSuppressionSkipsHeavyFields(t *testing.T) {
+	eh := NewErrorHandler(&Config{ShowSourceCode: true, MaxFrames: 10, Suppress: []Suppression{{Message: "noisy"}}})
+
+	data := eh.errorSummary(nil, New("noisy", ErrUnknown, nil))
+	assert.True(t, eh.suppressed("noisy", data))
+	assert.NotEmpty(t, data.Fingerprint)
+	assert.Empty(t, data.RawStack)
+	assert.Nil(t, data.Groups)
+	assert.False(t, hasSnippets(data.Frames))
+}
@@ -0,0 +1,91 @@
+//go:build !js
+
+package xerr
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/iMohamedSheta/xerr/core"
+)
+
+// SourceFetcher returns the content of a source file referenced by a stack frame
+type SourceFetcher interface {
+	Fetch(file string) ([]byte, error)
+}
+
+// GitFetcher reads source files as they were at a given revision of a local git checkout
+type GitFetcher struct {
+	Dir        string // Path to the local checkout
+	Rev        string // Commit, tag or branch to read files from
+	SourceRoot string // Path prefix of frame files to strip (defaults to Dir)
+}
+
+// Fetch returns the content of file at the configured revision
+func (g *GitFetcher) Fetch(file string) ([]byte, error) {
+	if g.Rev == "" || strings.HasPrefix(g.Rev, "-") {
+		return nil, fmt.Errorf("invalid revision %q", g.Rev)
+	}
+
+	rel, err := g.relPath(file)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("git", "-C", g.Dir, "show", g.Rev+":"+rel)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git show %s:%s: %s", g.Rev, rel, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// relPath maps an absolute frame file to a path relative to the repository root
+func (g *GitFetcher) relPath(file string) (string, error) {
+	root := g.SourceRoot
+	if root == "" {
+		root = g.Dir
+	}
+
+	rel, err := filepath.Rel(root, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("file %s is outside of source root %s", file, root)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// Resolve re-renders the frame snippets using sources from f, e.g. to review an
+// old error against the code at the commit it was raised from.
+// Frames whose source can't be fetched get a message explaining why.
+// The hashes are recomputed for the new snippets.
+func (d *ErrorData) Resolve(f SourceFetcher) {
+	for i := range d.Frames {
+		fr := &d.Frames[i]
+		src, err := f.Fetch(fr.File)
+		if err != nil {
+			fr.Snippet = "Could not fetch source: " + err.Error()
+			continue
+		}
+		fr.Snippet = core.FormatSnippet(src, fr.Line)
+	}
+
+	for i := range d.Groups {
+		g := &d.Groups[i]
+		for j := range g.Frames {
+			if k := g.Start + j; k < len(d.Frames) {
+				g.Frames[j] = d.Frames[k]
+			}
+		}
+	}
+	hashSnippets(d.Frames)
+	for i := range d.Groups {
+		hashSnippets(d.Groups[i].Frames)
+	}
+}
@@ -0,0 +1,77 @@
+//go:build !js
+
+package xerr
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gitRepo creates a repository with two commits of main.go and returns the dir and first commit
+func gitRepo(t *testing.T) (string, string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return string(out)
+	}
+
+	git("init", "-q")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc old() {}\n"), 0644))
+	git("add", "main.go")
+	git("commit", "-q", "-m", "first")
+	first := git("rev-parse", "HEAD")[:40]
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc current() {}\n"), 0644))
+	git("commit", "-q", "-am", "second")
+	return dir, first
+}
+
+func TestGitFetcherReadsFileAtRevision(t *testing.T) {
+	dir, first := gitRepo(t)
+
+	src, err := (&GitFetcher{Dir: dir, Rev: first}).Fetch(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "func old()")
+
+	// Frames recorded on another machine are mapped through SourceRoot
+	src, err = (&GitFetcher{Dir: dir, Rev: "HEAD", SourceRoot: "/build/app"}).Fetch("/build/app/main.go")
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "func current()")
+}
+
+func TestGitFetcherErrors(t *testing.T) {
+	dir, _ := gitRepo(t)
+
+	_, err := (&GitFetcher{Dir: dir, Rev: "--output=x"}).Fetch(filepath.Join(dir, "main.go"))
+	assert.Error(t, err)
+
+	_, err = (&GitFetcher{Dir: dir, Rev: "HEAD"}).Fetch("/elsewhere/main.go")
+	assert.ErrorContains(t, err, "outside of source root")
+
+	_, err = (&GitFetcher{Dir: dir, Rev: "HEAD"}).Fetch(filepath.Join(dir, "missing.go"))
+	assert.ErrorContains(t, err, "git show HEAD:missing.go")
+}
+
+func TestErrorDataResolve(t *testing.T) {
+	dir, first := gitRepo(t)
+	file := filepath.Join(dir, "main.go")
+
+	frames := []Frame{{Function: "main.old", File: file, Line: 3, Snippet: "stale"}, {Function: "main.gone", File: "/other/x.go", Line: 1}}
+	data := &ErrorData{Frames: frames, Groups: groupFrames(frames, nil)}
+	data.Resolve(&GitFetcher{Dir: dir, Rev: first})
+
+	assert.Contains(t, data.Frames[0].Snippet, ">>    3 | func old() {}")
+	assert.Contains(t, data.Frames[1].Snippet, "Could not fetch source")
+	assert.Equal(t, data.Frames[0].Snippet, data.Groups[0].Frames[0].Snippet)
+}
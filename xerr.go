@@ -1,14 +1,22 @@
 package xerr
 
 import (
+	"bytes"
 	"embed"
 	"fmt"
 	"html/template"
+	"io"
+	"log/slog"
 	"net/http"
-	"os"
+	"reflect"
 	"runtime"
+	"runtime/debug"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/iMohamedSheta/xerr/core"
 )
 
 //go:embed assets/templates/*.html
@@ -17,37 +25,75 @@ var templatesFS embed.FS
 // the executed template name
 const execTemplate = "error.html"
 
-// Frame represents a single stack frame
-type Frame struct {
-	Function string
-	File     string
-	Line     int
-	Snippet  string
+// FrameGroup is a run of consecutive frames from the same package
+type FrameGroup struct {
+	Package   string
+	Frames    []Frame
+	Start     int  // Index of the first frame in ErrorData.Frames
+	Collapsed bool // Whether the group is rendered collapsed
+}
+
+// Cause is one error of the wrapped error chain
+type Cause struct {
+	Message string
+	Type    ErrorType // Type of the error when it's an XErr
+	XErr    bool      // Whether the error is an XErr
+	Depth   int       // Nesting depth in the chain, 0 for the handled error
 }
 
 // ErrorData contains all the information needed to render an error page
 type ErrorData struct {
-	Error     string
-	Frames    []Frame
-	Timestamp time.Time
-	Method    string
-	URL       string
-	UserAgent string
-	GoVersion string
-	OS        string
-	Arch      string
-	Request   *http.Request
+	ID          string // Random identifier of this occurrence, also written to the access log
+	Error       string
+	Causes      []Cause // The wrapped error chain, cut after MaxChainDepth errors
+	Truncated   bool    // Whether Causes was cut short by the depth limit or a cycle
+	DetailsErr  string  `json:",omitempty"` // Details schema violations of the XErr in the chain, see SetSchemaValidation
+	Fingerprint string  // Groups errors of the same kind raised from the same place
+	Frames      []Frame
+	Groups      []FrameGroup // Frames grouped by package
+	RawStack    string       // Raw debug.Stack() output, kept as a fallback for parsed frames
+	Timestamp   time.Time
+	Method      string
+	URL         string
+	UserAgent   string
+	GoVersion   string        `json:"-"` // Deprecated: use Env.GoVersion
+	OS          string        `json:"-"` // Deprecated: use Env.OS
+	Arch        string        `json:"-"` // Deprecated: use Env.Arch
+	Env         EnvSnapshot   // Go version, platform and recorded environment variables
+	EnvDiff     []EnvDiff     `json:"-"` // Differences with the local environment, set by RenderReport
+	Request     *http.Request `json:"-"` // The live request, only valid while HandleError runs
+	RequestInfo *RequestInfo  // Snapshot of the request, safe to keep after the response is written
+	Duration    time.Duration // Time spent in the request before the error, when handled by the middleware
+	Baseline    *Baseline     // Sampled timings of successful requests to the same route, if any
+	Breadcrumbs []Breadcrumb  // Steps reached by the request before the error, see AddBreadcrumb
+	Signature   string        `json:"-"` // HMAC of the JSON encoding set on reporter projections when Config.SigningKey is set, see Sign
 }
 
-// Config holds configuration options for the error handler
+// Config holds configuration options for the error handler.
+// Its JSON form, read by ParseConfig, uses camelCase keys.
 type Config struct {
-	ShowSourceCode bool   // Whether to show source code snippets
-	MaxFrames      int    // Maximum number of stack frames to display
-	Environment    string // Environment name (development, production, etc.)
-	DebugMode      bool   // Whether debug mode is enabled
-	SkipFrames     int    // Number of frames to skip from the top
-	SkipLibrary    bool   // Whether to skip the library frames
-	TemplatePath   string // Path to custom template file (optional)
+	ShowSourceCode   bool             `json:"showSourceCode"`  // Whether to show source code snippets
+	MaxFrames        int              `json:"maxFrames"`       // Maximum number of stack frames to display
+	Environment      string           `json:"environment"`     // Environment name (development, production, etc.)
+	DebugMode        bool             `json:"debugMode"`       // Whether debug mode is enabled
+	PublicJSON       bool             `json:"publicJSON"`      // Write only the public JSON error instead of the error page
+	SkipFrames       int              `json:"skipFrames"`      // Number of frames to skip from the top
+	SkipLibrary      bool             `json:"skipLibrary"`     // Whether to skip the library frames
+	TemplatePath     string           `json:"templatePath"`    // Path to custom template file (optional)
+	SampleRate       float64          `json:"sampleRate"`      // Fraction of successful requests whose timings and breadcrumbs are sampled by the middleware (0 disables)
+	MaxSamples       int              `json:"maxSamples"`      // Maximum number of sampled requests kept per route
+	Suppress         []Suppression    `json:"suppress"`        // Known noisy errors that get a plain response instead of the error page
+	Reporters        []Reporter       `json:"-"`               // Receive every handled error that isn't suppressed
+	ReporterConfigs  []ReporterConfig `json:"reporters"`       // Registered reporters to create by name
+	OnReportError    func(error)      `json:"-"`               // Called when a reporter fails (optional)
+	CriticalReporter Reporter         `json:"-"`               // Must-not-lose reporter, receives critical errors before the response is written
+	CriticalTimeout  Duration         `json:"criticalTimeout"` // Bound for critical deliveries (2s when zero)
+	AccessLog        *slog.Logger     `json:"-"`               // Logs one line per request handled by the middleware (optional)
+	Chaos            *Chaos           `json:"chaos"`           // Injects synthetic failures in the middleware (development only)
+	SigningKey       []byte           `json:"-"`               // Key used to sign the data passed to reporters (optional)
+	EnvKeys          []string         `json:"envKeys"`         // Environment variables recorded with errors (DefaultEnvKeys when nil)
+	Renderer         Renderer         `json:"-"`               // Renders the error page (the template when nil)
+	RendererConfig   *RendererConfig  `json:"renderer"`        // Registered renderer to create by name, used when Renderer is nil
 }
 
 // DefaultConfig returns a default configuration
@@ -60,13 +106,24 @@ func DefaultConfig() *Config {
 		SkipFrames:     2, // Skip the panic, recover, and this function
 		SkipLibrary:    false,
 		TemplatePath:   "", // Empty means use embedded template
+		SampleRate:     0,  // Sampling disabled
+		MaxSamples:     100,
 	}
 }
 
 // ErrorHandler handles errors and renders the error page
 type ErrorHandler struct {
-	config *Config
-	tpl    *template.Template
+	config          *Config
+	tpl             *template.Template
+	renderer        Renderer
+	samples         *sampleStore
+	suppressions    []compiledSuppression
+	suppressedCount atomic.Uint64
+	reporters       []Reporter
+	responses       *responseCache
+	reports         inflight
+	reportFields    Field // Heavy fields needed by at least one reporter
+	publicOnly      bool  // Whether HandleError only writes the public JSON error, see HandleError
 }
 
 // NewErrorHandler creates a new ErrorHandler with the given configuration
@@ -75,62 +132,237 @@ func NewErrorHandler(config *Config) *ErrorHandler {
 		config = DefaultConfig()
 	}
 
-	var tpl *template.Template
-	var err error
+	eh := &ErrorHandler{
+		config:    config,
+		reporters: slices.Clone(config.Reporters),
+		responses: newResponseCache(),
+	}
+	if config.SampleRate > 0 {
+		eh.samples = newSampleStore(config.MaxSamples)
+	}
+
+	suppressions, err := compileSuppressions(config.Suppress)
+	if err != nil {
+		panic(err.Error())
+	}
+	eh.suppressions = suppressions
+
+	for _, rc := range config.ReporterConfigs {
+		reporter, err := NewReporter(rc.Name, rc.Options)
+		if err != nil {
+			panic(err.Error())
+		}
+		eh.reporters = append(eh.reporters, reporter)
+	}
+	eh.reportFields = fieldsOf(eh.reporters, config.CriticalReporter)
+	eh.publicOnly = config.PublicJSON && len(eh.reporters) == 0 && config.CriticalReporter == nil && len(eh.suppressions) == 0
+
+	tr, err := newTemplateRenderer(config.TemplatePath)
+	if err != nil {
+		panic(err.Error())
+	}
+	eh.tpl = tr.tpl
+	eh.renderer = tr
+
+	if config.Renderer != nil {
+		eh.renderer = config.Renderer
+	} else if rc := config.RendererConfig; rc != nil {
+		renderer, err := NewRenderer(rc.Name, rc.Options)
+		if err != nil {
+			panic(err.Error())
+		}
+		eh.renderer = renderer
+	}
+
+	return eh
+}
+
+// templateRenderer renders the error page with an html/template
+type templateRenderer struct {
+	tpl *template.Template
+}
+
+// newTemplateRenderer parses the template at path, or the embedded one when path is empty
+func newTemplateRenderer(path string) (*templateRenderer, error) {
+	tr := &templateRenderer{}
+	funcs := tr.funcMap()
 
 	// Use custom template if provided, otherwise use embedded template
-	if config.TemplatePath != "" {
-		tpl, err = template.New(execTemplate).Funcs(templateFuncs).ParseFiles(config.TemplatePath)
+	if path != "" {
+		tpl, err := parseTemplateFile(path, funcs)
 		if err != nil {
-			panic(fmt.Sprintf("failed to parse custom template: %v", err))
+			return nil, fmt.Errorf("failed to parse custom template: %w", err)
 		}
+		tr.tpl = tpl
 	} else {
-		tpl = template.Must(
-			template.New("").Funcs(templateFuncs).ParseFS(templatesFS, "assets/templates/*.html"),
+		tr.tpl = template.Must(
+			template.New("").Funcs(funcs).ParseFS(templatesFS, "assets/templates/*.html"),
 		)
 	}
+	return tr, nil
+}
 
-	return &ErrorHandler{
-		config: config,
-		tpl:    tpl,
-	}
+func (tr *templateRenderer) ContentType() string {
+	return "text/html; charset=utf-8"
 }
 
-// HandleError renders an error page for the given error and writes it to the ResponseWriter
+func (tr *templateRenderer) Render(w io.Writer, data *ErrorData) error {
+	return tr.tpl.ExecuteTemplate(w, execTemplate, data)
+}
+
+// HandleError renders an error page for the given error and writes it to the ResponseWriter.
+// With Config.PublicJSON only the public JSON error is written, and no ErrorData is
+// built when no reporter or suppression rule needs it.
 func (eh *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, err interface{}) {
+	if eh.publicOnly {
+		if eh.config.AccessLog != nil {
+			recordError(r, err, newErrorID())
+		}
+		eh.writePublic(w, err)
+		return
+	}
+
+	data := eh.errorSummary(r, err)
+	recordError(r, err, data.ID)
+	if eh.suppressed(err, data) {
+		eh.dispatchSuppressed(r, err, data)
+		eh.writeSuppressed(w, err)
+		return
+	}
+
+	fields := eh.reportFields
+	if !eh.config.PublicJSON {
+		fields = AllFields // the error page shows every field
+	}
+	eh.completeData(data, r, err, fields)
+	eh.dispatch(err, data)
+
+	if eh.config.PublicJSON {
+		eh.writePublic(w, err)
+		return
+	}
+
+	for key, value := range rateLimitHeaders(err) {
+		w.Header().Set(key, value)
+	}
+	e, _ := err.(error)
+	w.Header().Set("Content-Type", eh.renderer.ContentType())
+	w.WriteHeader(StatusCode(e))
+
+	if renderErr := eh.renderer.Render(w, data); renderErr != nil {
+		// Fallback to plain text if template rendering fails
+		_, _ = fmt.Fprintf(w, "Error: %v\n\nTemplate rendering failed: %v\n\n%s", err, renderErr, data.RawStack)
+	}
+}
+
+// envKeys returns the environment variables to record with errors
+func (eh *ErrorHandler) envKeys() []string {
+	if eh.config.EnvKeys == nil {
+		return DefaultEnvKeys
+	}
+	return eh.config.EnvKeys
+}
+
+// errorData collects everything known about err and the request it occurred in.
+// Heavy fields not included in fields are left empty.
+func (eh *ErrorHandler) errorData(r *http.Request, err interface{}, fields Field) *ErrorData {
+	data := eh.errorSummary(r, err)
+	eh.completeData(data, r, err, fields)
+	return data
+}
+
+// errorSummary collects what suppression rules match on: the message, the frames
+// without snippets and the fingerprint, and the cheap request fields
+func (eh *ErrorHandler) errorSummary(r *http.Request, err interface{}) *ErrorData {
 	data := &ErrorData{
+		ID:        newErrorID(),
 		Error:     fmt.Sprintf("%v", err),
 		Frames:    eh.stackFrames(err),
 		Timestamp: time.Now(),
-		GoVersion: strings.TrimPrefix(runtime.Version(), "go"),
-		OS:        runtime.GOOS,
-		Arch:      runtime.GOARCH,
 		Request:   r,
 	}
-
+	data.Fingerprint = fingerprint(err, data.Frames)
 	if r != nil {
 		data.Method = r.Method
 		data.URL = r.URL.String()
 		data.UserAgent = r.UserAgent()
 	}
+	return data
+}
 
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.WriteHeader(http.StatusInternalServerError)
+// completeData adds the rest of the data to a summary, leaving out the heavy fields
+// not included in fields
+func (eh *ErrorHandler) completeData(data *ErrorData, r *http.Request, err interface{}, fields Field) {
+	data.Env = CurrentEnv(eh.envKeys())
+	data.GoVersion, data.OS, data.Arch = data.Env.GoVersion, data.Env.OS, data.Env.Arch
+	if e, ok := err.(error); ok {
+		data.Causes, data.Truncated = causes(e)
+		if xe, ok := As(e); ok && xe.CheckDetails() != nil {
+			data.DetailsErr = xe.DetailsError().Error()
+		}
+	}
+	if fields&FieldSnippets != 0 {
+		eh.addSnippets(data.Frames)
+		hashSnippets(data.Frames)
+	}
+	if fields&FieldRawStack != 0 {
+		data.RawStack = string(debug.Stack())
+	}
+	if fields&FieldGroups != 0 {
+		data.Groups = groupFrames(data.Frames, r)
+	}
 
-	if renderErr := eh.tpl.ExecuteTemplate(w, execTemplate, data); renderErr != nil {
-		// Fallback to plain text if template rendering fails
-		_, _ = fmt.Fprintf(w, "Error: %v\n\nTemplate rendering failed: %v", err, renderErr)
+	if r != nil {
+		if fields&FieldRequest != 0 {
+			data.RequestInfo = newRequestInfo(r)
+		}
+		if state := stateFromRequest(r); state != nil {
+			data.Duration = time.Since(state.start)
+			data.Breadcrumbs = state.breadcrumbs()
+		}
+		if eh.samples != nil {
+			data.Baseline = eh.samples.baseline(routeKey(r))
+		}
 	}
 }
 
+// causes flattens the chain of err, see core.WalkChain
+func causes(err error) (list []Cause, truncated bool) {
+	truncated = WalkChain(err, func(e error, depth int) bool {
+		c := Cause{Message: e.Error(), Depth: depth}
+		if xe, ok := e.(*XErr); ok {
+			c.Message, c.Type, c.XErr = xe.Message, xe.Type, true
+		}
+		list = append(list, c)
+		return true
+	})
+	return list, truncated
+}
+
 // Middleware returns an HTTP middleware that catches panics and renders error pages
 func (eh *ErrorHandler) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, state := withRequestState(r)
+
+		// The status is only needed for sampling and the access log,
+		// otherwise the writer is passed through untouched
+		var sw *statusRecorder
+		if eh.samples != nil || eh.config.AccessLog != nil {
+			sw = &statusRecorder{ResponseWriter: w}
+			w = wrapRecorder(sw)
+		}
 		defer func() {
 			if rec := recover(); rec != nil {
 				eh.HandleError(w, r, rec)
+			} else {
+				eh.sampleRequest(r, sw.Status(), state)
 			}
+			eh.logAccess(r, sw.Status(), state)
 		}()
+
+		if eh.injectChaos(w, r) {
+			return
+		}
 		next.ServeHTTP(w, r)
 	})
 }
@@ -141,41 +373,16 @@ func (eh *ErrorHandler) codeSnippet(file string, line int) string {
 		return "Source code display disabled"
 	}
 
-	return codeSnippet(file, line)
+	return core.CodeSnippet(file, line)
 }
 
-// codeSnippet extracts a few lines around the error line
-func codeSnippet(file string, line int) string {
-	data, err := os.ReadFile(file)
-	if err != nil {
-		return "Could not read source file"
-	}
-
-	lines := strings.Split(string(data), "\n")
-	start := line - 15
-	if start < 0 {
-		start = 0
-	}
-	end := line + 20
-	if end > len(lines) {
-		end = len(lines)
-	}
-
-	var b strings.Builder
-	for i := start; i < end; i++ {
-		prefix := "   "
-		if i+1 == line {
-			prefix = ">> "
-		}
-		fmt.Fprintf(&b, "%s%4d | %s\n", prefix, i+1, lines[i])
-	}
-	return b.String()
-}
-
-// stackFrames extracts stack frames from the current goroutine
+// stackFrames extracts stack frames, without snippets, from the error chain when
+// an error in it carries a stack (see Frames), or from the current goroutine otherwise
 func (eh *ErrorHandler) stackFrames(err interface{}) []Frame {
-	if xerror, ok := err.(*XErr); ok {
-		return xerror.StackTrace(eh.config.ShowSourceCode)
+	if e, ok := err.(error); ok {
+		if frames := Frames(e); frames != nil {
+			return frames
+		}
 	}
 
 	pcs := make([]uintptr, eh.config.MaxFrames)
@@ -186,24 +393,13 @@ func (eh *ErrorHandler) stackFrames(err interface{}) []Frame {
 	for {
 		fr, more := iter.Next()
 		if fr.File != "" {
-			// Skip standard library and module cache files
-			if eh.config.SkipLibrary {
-				if strings.Contains(fr.File, "/iMohamedSheta/xerr/") ||
-					strings.Contains(fr.File, "/pkg/mod/") ||
-					strings.Contains(fr.File, "/vendor/") ||
-					strings.Contains(fr.File, "net/http") ||
-					strings.Contains(fr.File, "runtime/") {
-					if !more {
-						break
-					}
-					continue
+			frame := core.NewFrame(fr)
+			// Skip frames outside the application (standard library, dependencies)
+			if eh.config.SkipLibrary && !frame.App {
+				if !more {
+					break
 				}
-			}
-			frame := Frame{
-				Function: fr.Function,
-				File:     fr.File,
-				Line:     fr.Line,
-				Snippet:  eh.codeSnippet(fr.File, fr.Line),
+				continue
 			}
 			frames = append(frames, frame)
 
@@ -218,10 +414,132 @@ func (eh *ErrorHandler) stackFrames(err interface{}) []Frame {
 	return frames
 }
 
+// addSnippets sets the code snippet of every frame
+func (eh *ErrorHandler) addSnippets(frames []Frame) {
+	for i := range frames {
+		frames[i].Snippet = eh.codeSnippet(frames[i].File, frames[i].Line)
+	}
+}
+
+// funcMap returns the template functions bound to this renderer
+func (tr *templateRenderer) funcMap() template.FuncMap {
+	funcs := make(template.FuncMap, len(templateFuncs)+1)
+	for name, fn := range templateFuncs {
+		funcs[name] = fn
+	}
+	funcs["safe"] = tr.safe
+	return funcs
+}
+
+// safe executes a named sub-template or a function and returns its output.
+// Any error or panic is replaced by a placeholder so the rest of the page still renders.
+//
+//	{{safe "my-section" .}}
+//	{{safe .SomeFunc "arg"}}
+func (tr *templateRenderer) safe(target interface{}, args ...interface{}) (out template.HTML) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			out = safePlaceholder(fmt.Errorf("panic: %v", rec))
+		}
+	}()
+
+	switch t := target.(type) {
+	case string:
+		var data interface{}
+		if len(args) > 0 {
+			data = args[0]
+		}
+		var buf bytes.Buffer
+		if err := tr.tpl.ExecuteTemplate(&buf, t, data); err != nil {
+			return safePlaceholder(err)
+		}
+		return template.HTML(buf.String())
+	default:
+		result, err := callSafe(target, args)
+		if err != nil {
+			return safePlaceholder(err)
+		}
+		if h, ok := result.(template.HTML); ok {
+			return h
+		}
+		return template.HTML(template.HTMLEscapeString(fmt.Sprint(result)))
+	}
+}
+
+// callSafe calls fn with args, returning its first result and any trailing error result
+func callSafe(fn interface{}, args []interface{}) (interface{}, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return nil, fmt.Errorf("safe: %T is not a template name or function", fn)
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		in[i] = reflect.ValueOf(arg)
+	}
+	if v.Type().NumIn() != len(in) {
+		return nil, fmt.Errorf("safe: function expects %d arguments, got %d", v.Type().NumIn(), len(in))
+	}
+
+	out := v.Call(in)
+	if len(out) == 0 {
+		return "", nil
+	}
+	if last := out[len(out)-1]; last.Type() == reflect.TypeOf((*error)(nil)).Elem() {
+		if !last.IsNil() {
+			return nil, last.Interface().(error)
+		}
+		out = out[:len(out)-1]
+	}
+	if len(out) == 0 {
+		return "", nil
+	}
+	return out[0].Interface(), nil
+}
+
+// safePlaceholder renders the replacement for a failed safe section
+func safePlaceholder(err error) template.HTML {
+	return template.HTML(`<span class="safe-error">[section failed to render: ` + template.HTMLEscapeString(err.Error()) + `]</span>`)
+}
+
+// groupFrames groups consecutive frames by package.
+// Library groups start collapsed; the "xerr-expand" and "xerr-collapse" query
+// parameters (comma separated package names, or "all") override the default.
+func groupFrames(frames []Frame, r *http.Request) []FrameGroup {
+	var expand, collapse []string
+	if r != nil && r.URL != nil {
+		q := r.URL.Query()
+		expand = strings.Split(q.Get("xerr-expand"), ",")
+		collapse = strings.Split(q.Get("xerr-collapse"), ",")
+	}
+
+	var groups []FrameGroup
+	for i, f := range frames {
+		if n := len(groups); n > 0 && groups[n-1].Package == f.Package {
+			groups[n-1].Frames = append(groups[n-1].Frames, f)
+			continue
+		}
+		groups = append(groups, FrameGroup{Package: f.Package, Frames: []Frame{f}, Start: i})
+	}
+
+	for i := range groups {
+		g := &groups[i]
+		g.Collapsed = !g.Frames[0].App
+		if slices.Contains(expand, "all") || slices.Contains(expand, g.Package) {
+			g.Collapsed = false
+		}
+		if slices.Contains(collapse, "all") || slices.Contains(collapse, g.Package) {
+			g.Collapsed = true
+		}
+	}
+	return groups
+}
+
 // Template functions for the HTML template
 var templateFuncs = template.FuncMap{
 	"split":     strings.Split,
 	"contains":  strings.Contains,
+	"add":       func(a, b int) int { return a + b },
 	"trimSpace": strings.TrimSpace,
 	"parseCodeLine": func(line string) map[string]string {
 		result := map[string]string{
@@ -258,6 +576,8 @@ var templateFuncs = template.FuncMap{
 		switch s := v.(type) {
 		case []Frame:
 			return len(s)
+		case []Cause:
+			return len(s)
 		case string:
 			return len(s)
 		default:
@@ -1,9 +1,12 @@
 package xerr
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -160,3 +163,169 @@ func TestMiddleware_PanicRecovery(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, rw.Code)
 	assert.Contains(t, rw.Body.String(), "handler panic")
 }
+
+func TestSafeTemplateFuncRendersSubTemplate(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/error.html"
+	tpl := `{{define "ok"}}<b>{{.}}</b>{{end}}` +
+		`{{define "broken"}}{{index .Missing 5}}{{end}}` +
+		`{{safe "ok" "fine"}}|{{safe "broken" .}}|{{safe "missing"}}|{{.Error}}`
+	assert.NoError(t, os.WriteFile(path, []byte(tpl), 0644))
+
+	eh := NewErrorHandler(&Config{TemplatePath: path})
+	w := httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/", nil), "boom")
+
+	parts := strings.Split(w.Body.String(), "|")
+	assert.Len(t, parts, 4)
+	assert.Equal(t, "<b>fine</b>", parts[0])
+	assert.Contains(t, parts[1], "section failed to render")
+	assert.Contains(t, parts[2], "section failed to render")
+	assert.Equal(t, "boom", parts[3])
+}
+
+func TestSafeTemplateFuncRecoversFunctions(t *testing.T) {
+	tr, err := newTemplateRenderer("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "a &lt; b", string(tr.safe(func() string { return "a < b" })))
+	assert.Equal(t, "x-1", string(tr.safe(func(s string, n int) string { return fmt.Sprintf("%s-%d", s, n) }, "x", 1)))
+	assert.Contains(t, string(tr.safe(func() (string, error) { return "", errors.New("bad <data>") })), "bad &lt;data&gt;")
+	assert.Contains(t, string(tr.safe(func() string { panic("kaboom") })), "panic: kaboom")
+	assert.Contains(t, string(tr.safe(42)), "not a template name or function")
+}
+
+func TestHandleErrorIncludesRawStack(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/error.html"
+	assert.NoError(t, os.WriteFile(path, []byte(`{{.RawStack}}`), 0644))
+
+	eh := NewErrorHandler(&Config{TemplatePath: path})
+	w := httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/", nil), "boom")
+
+	assert.Contains(t, w.Body.String(), "goroutine")
+	assert.Contains(t, w.Body.String(), "TestHandleErrorIncludesRawStack")
+}
+
+func TestHandleErrorFallbackIncludesRawStack(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/error.html"
+	assert.NoError(t, os.WriteFile(path, []byte(`{{.Missing}}`), 0644))
+
+	eh := NewErrorHandler(&Config{TemplatePath: path})
+	w := httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/", nil), "boom")
+
+	assert.Contains(t, w.Body.String(), "Template rendering failed")
+	assert.Contains(t, w.Body.String(), "goroutine")
+}
+
+func TestGroupFramesGroupsConsecutivePackages(t *testing.T) {
+	frames := []Frame{
+		{Function: "main.handler", Package: "main", App: true},
+		{Function: "main.helper", Package: "main", App: true},
+		{Function: "net/http.HandlerFunc.ServeHTTP", Package: "net/http"},
+		{Function: "net/http.serverHandler.ServeHTTP", Package: "net/http"},
+		{Function: "main.main", Package: "main", App: true},
+	}
+
+	groups := groupFrames(frames, nil)
+	assert.Len(t, groups, 3)
+	assert.Equal(t, "main", groups[0].Package)
+	assert.Len(t, groups[0].Frames, 2)
+	assert.False(t, groups[0].Collapsed)
+	assert.Equal(t, "net/http", groups[1].Package)
+	assert.Equal(t, 2, groups[1].Start)
+	assert.True(t, groups[1].Collapsed, "Library groups should be collapsed by default")
+	assert.Equal(t, 4, groups[2].Start)
+
+	r := httptest.NewRequest(http.MethodGet, "/?xerr-expand=net/http&xerr-collapse=main", nil)
+	groups = groupFrames(frames, r)
+	assert.True(t, groups[0].Collapsed)
+	assert.False(t, groups[1].Collapsed)
+
+	r = httptest.NewRequest(http.MethodGet, "/?xerr-expand=all", nil)
+	for _, g := range groupFrames(frames, r) {
+		assert.False(t, g.Collapsed)
+	}
+}
+
+func TestHandleErrorRendersFrameGroups(t *testing.T) {
+	eh := NewErrorHandler(nil)
+	w := httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/", nil), "grouped")
+
+	assert.Contains(t, w.Body.String(), `class="frame-group"`)
+	assert.Contains(t, w.Body.String(), "github.com/iMohamedSheta/xerr")
+}
+
+func TestHandleErrorRendersCyclicCauseChain(t *testing.T) {
+	eh := NewErrorHandler(&Config{MaxFrames: 10})
+	outer := New("outer", ErrUnknown, nil)
+	inner := New("inner", ErrRateLimited, outer)
+	outer.Err = inner
+	r := httptest.NewRequest(http.MethodGet, "/cycle", nil)
+	w := httptest.NewRecorder()
+
+	eh.HandleError(w, r, fmt.Errorf("request failed: %w", outer))
+
+	body := w.Body.String()
+	assert.Contains(t, body, "Causes:")
+	assert.Contains(t, body, "[type -1] inner")
+	assert.Contains(t, body, "(chain truncated)")
+}
+
+func TestErrorDataCauses(t *testing.T) {
+	eh := NewErrorHandler(nil)
+	err := New("save failed", ErrUnknown, errors.New("disk full"))
+
+	data := eh.errorData(nil, err, AllFields)
+
+	assert.False(t, data.Truncated)
+	assert.Equal(t, []Cause{
+		{Message: "save failed", Type: ErrUnknown, XErr: true},
+		{Message: "disk full", Depth: 1},
+	}, data.Causes)
+}
+
+// stackError carries the stack of the place it was created, like pkg/errors
+type stackError struct {
+	stack []uintptr
+}
+
+func newStackError() error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	return &stackError{stack: pcs[:n]}
+}
+
+func (e *stackError) Error() string { return "stack error" }
+
+func (e *stackError) Callers() []uintptr { return e.stack }
+
+func TestStackFramesUseCarriedStack(t *testing.T) {
+	eh := NewErrorHandler(&Config{ShowSourceCode: true, MaxFrames: 10})
+	err := fmt.Errorf("handler: %w", newStackError())
+
+	frames := eh.errorData(nil, err, AllFields).Frames
+
+	assert.NotEmpty(t, frames)
+	assert.Equal(t, "TestStackFramesUseCarriedStack", frames[0].Name)
+	assert.NotEmpty(t, frames[0].Snippet)
+}
+
+func TestHandleErrorShowsDetailsSchemaViolations(t *testing.T) {
+	const errOrder ErrorType = 2000
+	RegisterSchema(errOrder, Schema{Required: []string{"order_id"}})
+	defer UnregisterSchema(errOrder)
+	SetSchemaValidation(func(*XErr, error) {})
+	defer SetSchemaValidation(nil)
+
+	eh := NewErrorHandler(&Config{MaxFrames: 10})
+	w := httptest.NewRecorder()
+	eh.HandleError(w, httptest.NewRequest(http.MethodGet, "/", nil), New("order failed", errOrder, nil))
+
+	assert.Contains(t, w.Body.String(), "Details schema:")
+	assert.Contains(t, w.Body.String(), "missing required detail &#34;order_id&#34;")
+}